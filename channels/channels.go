@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sync"
 	"time"
+
+	"channels/hashing"
+	"channels/pool"
 )
 
 func SleepAndWakeUp(seconds int, ch chan string) {
@@ -22,41 +27,46 @@ func SleepAndWakeUp(seconds int, ch chan string) {
 	ch <- retval
 }
 
-// This function pretty much only opens the file and
-// hashes it. It is completely channel un-aware!
-func HashFile(fp string) (string, error) {
-	thisFile, err := os.Open(fp)
-	if err != nil {
-		return "", err
-	}
-	// always defer file closure!
-	defer thisFile.Close()
-
-	hash := sha512.New()
-	if _, err := io.Copy(hash, thisFile); err != nil {
-		return "", err
-	}
-
-	return hex.EncodeToString(hash.Sum(nil)), nil
-}
+// The actual hashing (HashFile, and the Result struct that a worker sends back for
+// every file) lives in the hashing package in ./hashing, so that other programs
+// can import it. Everything in here is about the channels around it.
 
 // Note the directions of the channels stated in the parameter list, as well as
 // the pointer to the waitgroup that we define in the main function! We know that
 // we can only ingest items from the files channel, and output only to the results
 // channel.
-func worker(files <-chan string, results chan<- map[string]string, wg *sync.WaitGroup) {
+//
+// The context is how we tell a worker to knock it off early. Without it, if
+// whoever reads the results channel stops reading, every worker gets stuck forever
+// trying to send a result nobody will ever take, and that's a goroutine leak. So
+// every time we'd block on a channel, we use a select that also watches ctx.Done().
+func worker(ctx context.Context, files <-chan string, results chan<- hashing.Result, wg *sync.WaitGroup) {
 	// This basically says once we're done with everything, decrement the
 	// waitGroup value regardless of anything.
 	defer wg.Done()
 
-	for file := range files {
-		hash, err := HashFile(file)
-		if err != nil {
-			fmt.Printf("Failed to hash file %s: %v\n", file, err)
-			continue
+	for {
+		var file string
+		select {
+		case f, ok := <-files:
+			if !ok {
+				// channel's closed, no more work
+				return
+			}
+			file = f
+		case <-ctx.Done():
+			return
+		}
+
+		// Even if this fails, send it along. The error is in the Result,
+		// and printing it here would just hide it from whoever is reading
+		// the results channel.
+		digest, size, err := hashing.HashFile(ctx, file)
+		select {
+		case results <- hashing.Result{Path: file, Digest: digest, Size: size, Err: err}:
+		case <-ctx.Done():
+			return
 		}
-		// put the file name and the hash value into the map
-		results <- map[string]string{file: hash}
 	}
 
 	// and since we're not returning anything and the defer directive handles the
@@ -64,6 +74,12 @@ func worker(files <-chan string, results chan<- map[string]string, wg *sync.Wait
 }
 
 func main() {
+	// Everything below shares this context. signal.NotifyContext cancels it when you
+	// hit Ctrl-C, and every goroutine that's watching it will wrap up and quit. You
+	// could just as easily use context.WithTimeout() to give up after a while.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	// All channels must be created with the make() builtin function.
 	ch := make(chan string, 2)
 	ch <- "Hello World!"
@@ -168,8 +184,9 @@ func main() {
 
 	// The second channel will be the results channel, also 10 items long. The workers
 	// will put the results of hashing into this channel, which will be read by an
-	// anonymous function displayed later. Also this will be a map just to be silly.
-	resultsChannel := make(chan map[string]string, 10)
+	// anonymous function displayed later. Each one is a Result struct, so it has the
+	// path, the hash, the size, and an error if something went wrong.
+	resultsChannel := make(chan hashing.Result, 10)
 
 	// Also, I will be utilizing the waitGroup library! This is crucial to ensure that
 	// work is done before terminating the program!
@@ -182,7 +199,7 @@ func main() {
 	// on each file input to the filesChannel.
 	for i := 0; i < 5; i++ {
 		wg.Add(1)
-		go worker(filesChannel, resultsChannel, &wg)
+		go worker(ctx, filesChannel, resultsChannel, &wg)
 	}
 
 	// Now I'll use TWO anonymous functions! The first has the over-arching instruction
@@ -198,8 +215,15 @@ func main() {
 			if !info.IsDir() {
 				// as long as this isn't a directory, this is
 				// a file that we want to hash. So we'll add
-				// it to the filesChannel.
-				filesChannel <- path
+				// it to the filesChannel. Unless we've been
+				// cancelled, in which case the workers aren't
+				// reading anymore and we'd block here forever.
+				// Returning the error stops the walk.
+				select {
+				case filesChannel <- path:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
 			return nil
 		})
@@ -226,9 +250,11 @@ func main() {
 
 	// Now we can process the results as soon as we receive them in real time!
 	for result := range resultsChannel {
-		for k, v := range result {
-			fmt.Printf("File: %s, Hash: %s\n", k, v)
+		if result.Err != nil {
+			fmt.Printf("Failed to hash file %s: %v\n", result.Path, result.Err)
+			continue
 		}
+		fmt.Printf("File: %s, Hash: %s\n", result.Path, result.Digest)
 	}
 
 	fmt.Printf("Second hash took: %s\n", time.Since(t2Now))
@@ -236,6 +262,40 @@ func main() {
 	// On my PC here, the first half took 24 seconds to complete. The second hash running concurrently took
 	// only 941ms! Huge difference when working on multiple files!
 
+	// That's a lot of plumbing to copy into every program though. The pool package in ./pool
+	// is all of that same setup wrapped up in one function, pool.Run(). You give it how many
+	// workers you want, an iterator of things to work on, and the function to run on each
+	// one, and it gives you back a channel of results. Each result has the input it came
+	// from, the output, and an error if there was one, so nothing gets lost in a Printf.
+	// hashing.Walk finds the files, and if it can't get into a directory, that error
+	// comes through as one of the inputs so it ends up in the results too.
+	t3Now := time.Now()
+
+	hashResults := pool.Run(ctx, 5, hashing.Walk(myFileDir), func(ctx context.Context, f hashing.File) (string, error) {
+		if f.Err != nil {
+			return "", f.Err
+		}
+		digest, _, err := hashing.HashFile(ctx, f.Path)
+		return digest, err
+	})
+
+	for result := range hashResults {
+		if result.Err != nil {
+			fmt.Printf("Failed to hash file %s: %v\n", result.In.Path, result.Err)
+			continue
+		}
+		fmt.Printf("File: %s, Hash: %s\n", result.In.Path, result.Out)
+	}
+
+	fmt.Printf("Third hash with the pool package took: %s\n", time.Since(t3Now))
+
+	// And if all you care about is whether _everything_ worked, hashing.HashDir does
+	// all of the above and gives you one error at the end. It can either stop at the
+	// first failure or keep going and tell you about all of them.
+	if _, err := hashing.HashDir(ctx, myFileDir, 5, hashing.CollectAll); err != nil {
+		fmt.Printf("Some files failed to hash:\n%v\n", err)
+	}
+
 	// Now let's work on Mutexes. I'll call the function here but all the code
 	// and commentary are in mutex.go.
 	GuestbookInAction()
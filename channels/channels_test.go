@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"channels/hashing"
+
+	"go.uber.org/goleak"
+)
+
+// Start the workers with plenty of files to hash and nobody reading the results.
+// They all end up stuck trying to send, and cancelling has to get every one of
+// them out of there.
+func TestWorkerCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	files := make(chan string, 100)
+	for range cap(files) {
+		files <- path
+	}
+	results := make(chan hashing.Result) // never read
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go worker(ctx, files, results, &wg)
+	}
+
+	// Give them a moment to actually get stuck before pulling the plug.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("workers didn't stop after cancel")
+	}
+
+	goleak.VerifyNone(t)
+}
+
+// Same thing, but the workers are waiting on an empty files channel that never
+// gets closed.
+func TestWorkerCancelWaitingForFiles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go worker(ctx, make(chan string), make(chan hashing.Result), &wg)
+	}
+	cancel()
+	wg.Wait()
+
+	goleak.VerifyNone(t)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"slices"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// algorithms maps each -algo name to a function that makes a fresh hash. Every one
+// of these hands back a hash.Hash, so the rest of the program doesn't care which
+// one it's using.
+var algorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"blake2b": func() hash.Hash {
+		// blake2b.New512 only errors if you give it a key that's too long,
+		// and we're not giving it a key at all.
+		h, _ := blake2b.New512(nil)
+		return h
+	},
+}
+
+// algorithmNames lists the -algo choices in order, for the usage message.
+func algorithmNames() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Entry is one hashed file. The field tags are what it looks like in JSON output
+// (same idea as the Game struct in the marshalling module).
+type Entry struct {
+	Path   string `json:"path"`
+	Algo   string `json:"algo"`
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
@@ -0,0 +1,143 @@
+// hashdir hashes every file under one or more directories in parallel, like a
+// `sha512sum -r` that doesn't make you find(1) everything first.
+//
+//	hashdir [-algo sha256|sha512|md5|blake2b] [-j N] [-format sha512sum|json|csv] PATH...
+//
+// The default output can be checked later with `sha512sum -c` (or sha256sum,
+// md5sum, b2sum, to match -algo), or with hashdir itself:
+//
+//	hashdir -c MANIFEST [-algo ...] [-j N] [PATH...]
+//
+// which also understands the JSON format, and reports files under PATH that the
+// manifest doesn't list.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strings"
+
+	"channels/hashing"
+	"channels/pool"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run does all the work and returns the exit status. Keeping this out of main
+// means the deferred cleanup actually runs, since os.Exit skips defers.
+func run() int {
+	algo := flag.String("algo", "sha512", "hash algorithm: "+strings.Join(algorithmNames(), ", "))
+	jobs := flag.Int("j", runtime.NumCPU(), "number of files to hash at once")
+	format := flag.String("format", "sha512sum", "output format: sha512sum, json, csv")
+	check := flag.String("c", "", "verify files against this manifest instead of hashing them")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hashdir [flags] PATH...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       hashdir -c MANIFEST [flags] [PATH...]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	_, ok := algorithms[*algo]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "hashdir: unknown algorithm %q\n", *algo)
+		return 2
+	}
+	write, ok := formats[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "hashdir: unknown format %q\n", *format)
+		return 2
+	}
+	if flag.NArg() == 0 && *check == "" {
+		flag.Usage()
+		return 2
+	}
+
+	// Ctrl-C cancels this, which stops the walk and every worker.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Checking against a manifest is a whole different job, over in verify.go.
+	if *check != "" {
+		return verify(ctx, *check, flag.Args(), *algo, *jobs)
+	}
+
+	return hashRoots(ctx, flag.Args(), *algo, *jobs, write)
+}
+
+// hashRoots hashes every file under roots with algo and writes them all to stdout
+// with write, returning the exit status.
+func hashRoots(ctx context.Context, roots []string, algo string, workers int, write func(io.Writer, []Entry) error) int {
+	newHash := algorithms[algo]
+
+	// `hashdir dir > dir/MANIFEST` is the obvious way to keep a manifest with the
+	// files it describes, but the shell creates MANIFEST before we start, so the
+	// walk finds it. Hashing our own half-written output would put a line in the
+	// manifest that's wrong by the time we're done, and then sha512sum -c fails
+	// on it. So if stdout is a file, leave that one file out.
+	stdout, err := os.Stdout.Stat()
+	if err != nil || !stdout.Mode().IsRegular() {
+		stdout = nil
+	}
+	files := func(yield func(hashing.File) bool) {
+		for f := range hashing.Walk(roots...) {
+			if stdout != nil && f.Err == nil {
+				if info, err := os.Stat(f.Path); err == nil && os.SameFile(info, stdout) {
+					continue
+				}
+			}
+			if !yield(f) {
+				return
+			}
+		}
+	}
+
+	// The walking and hashing are the same as in the channels program, so they come
+	// from the hashing package. If walking runs into an error, it comes through as
+	// a File with Err set, and gets reported like any other failure.
+	results := pool.Run(ctx, workers, files, func(ctx context.Context, f hashing.File) (Entry, error) {
+		if f.Err != nil {
+			return Entry{}, f.Err
+		}
+		digest, size, err := hashing.HashFileWith(ctx, f.Path, newHash)
+		return Entry{Path: f.Path, Algo: algo, Digest: digest, Size: size}, err
+	})
+
+	// Like sha512sum, a file we can't read gets complained about and skipped, but
+	// the exit status says something went wrong.
+	status := 0
+	var entries []Entry
+	for r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "hashdir: %v\n", r.Err)
+			status = 1
+			continue
+		}
+		entries = append(entries, r.Out)
+	}
+
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "hashdir: %v\n", err)
+		return 1
+	}
+
+	// The workers finish in whatever order they feel like, so sort by path to
+	// make the output the same every time. That way two runs can be diffed.
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if err := write(os.Stdout, entries); err != nil {
+		fmt.Fprintf(os.Stderr, "hashdir: %v\n", err)
+		return 1
+	}
+	return status
+}
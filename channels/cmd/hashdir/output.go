@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// formats maps each -format name to the function that writes it.
+var formats = map[string]func(io.Writer, []Entry) error{
+	"sha512sum": writeSum,
+	"json":      writeJSON,
+	"csv":       writeCSV,
+}
+
+// writeSum writes the same format sha512sum (and sha256sum, md5sum, b2sum) print,
+// which is the digest, two spaces, and the path. That means the output can be fed
+// right back into `sha512sum -c` to check the files later.
+//
+// The one weird part is file names with a newline or backslash in them, which
+// would break the one-file-per-line format. The coreutils tools handle that by
+// escaping them and putting a backslash at the very start of the line, so we do
+// the same thing.
+func writeSum(out io.Writer, entries []Entry) error {
+	w := bufio.NewWriter(out)
+	for _, e := range entries {
+		path := e.Path
+		if strings.ContainsAny(path, "\\\n") {
+			path = sumEscaper.Replace(path)
+			w.WriteByte('\\')
+		}
+		fmt.Fprintf(w, "%s  %s\n", e.Digest, path)
+	}
+	return w.Flush()
+}
+
+var sumEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+
+// writeJSON writes every entry as one JSON array.
+func writeJSON(out io.Writer, entries []Entry) error {
+	// An empty list should come out as [] and not null.
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// writeCSV writes a header row and then one row per entry. encoding/csv takes care
+// of quoting anything with commas or quotes in it.
+func writeCSV(out io.Writer, entries []Entry) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"path", "algo", "digest", "size"})
+	for _, e := range entries {
+		w.Write([]string{e.Path, e.Algo, e.Digest, strconv.FormatInt(e.Size, 10)})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"channels/hashing"
+	"channels/pool"
+)
+
+// Hashing a tree is only half the job. The other half is coming back later and
+// checking that nothing changed. This is the -c mode: read a manifest (either the
+// sha512sum-style text or the JSON that hashdir writes), hash every file it lists
+// with the same worker pool, and report on each one:
+//
+//	OK        the file is there and the hash matches
+//	MISMATCH  the file is there but the hash doesn't match
+//	MISSING   the manifest lists it but it's gone
+//	NEW       it's under one of the PATHs but the manifest doesn't list it
+//
+// NEW only works if you give it the PATHs to look through, since otherwise it has
+// no idea where to look for files that aren't in the manifest.
+
+// The statuses a file can end up with.
+const (
+	statusOK       = "OK"
+	statusMismatch = "MISMATCH"
+	statusMissing  = "MISSING"
+	statusNew      = "NEW"
+)
+
+// verify checks the files listed in manifestPath and returns the exit status: 0 if
+// everything is OK, 1 if anything isn't.
+func verify(ctx context.Context, manifestPath string, roots []string, defaultAlgo string, workers int) int {
+	manifest, err := readManifest(manifestPath, defaultAlgo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hashdir: %v\n", err)
+		return 1
+	}
+
+	// If the manifest lives inside one of the directories being checked (which is
+	// the obvious place to keep it), it'd show up as NEW, or as a MISMATCH if it
+	// was hashed into itself while it was still being written. It's not one of
+	// the files being checked, so leave it out of everything.
+	manifestAbs, _ := filepath.Abs(manifestPath)
+	isManifest := func(path string) bool {
+		abs, err := filepath.Abs(path)
+		return err == nil && abs == manifestAbs
+	}
+	manifest = slices.DeleteFunc(manifest, func(e Entry) bool {
+		return isManifest(e.Path)
+	})
+
+	// Check every entry has an algorithm we know before we start, rather than
+	// failing every file one by one.
+	for _, e := range manifest {
+		if _, ok := algorithms[e.Algo]; !ok {
+			fmt.Fprintf(os.Stderr, "hashdir: %s: unknown algorithm %q\n", e.Path, e.Algo)
+			return 1
+		}
+	}
+
+	results := pool.Run(ctx, workers, slices.Values(manifest), func(ctx context.Context, e Entry) (string, error) {
+		digest, _, err := hashing.HashFileWith(ctx, e.Path, algorithms[e.Algo])
+		if errors.Is(err, fs.ErrNotExist) {
+			return statusMissing, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(digest, e.Digest) {
+			return statusMismatch, nil
+		}
+		return statusOK, nil
+	})
+
+	// Every path ends up in here with its status, so it can be printed in order.
+	statuses := make(map[string]string, len(manifest))
+	failed := 0
+	for r := range results {
+		if r.Err != nil {
+			// Couldn't even read it. That's a problem, but not one of the four
+			// statuses, so it goes to stderr like any other error.
+			fmt.Fprintf(os.Stderr, "hashdir: %v\n", r.Err)
+			failed++
+			continue
+		}
+		statuses[filepath.Clean(r.In.Path)] = r.Out
+	}
+
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "hashdir: %v\n", err)
+		return 1
+	}
+
+	// Anything under the roots that the manifest doesn't know about is new.
+	// Walking is cheap next to hashing, so this doesn't need workers.
+	if len(roots) > 0 {
+		listed := make(map[string]bool, len(manifest))
+		for _, e := range manifest {
+			listed[filepath.Clean(e.Path)] = true
+		}
+
+		for f := range hashing.Walk(roots...) {
+			if f.Err != nil {
+				fmt.Fprintf(os.Stderr, "hashdir: %v\n", f.Err)
+				failed++
+				continue
+			}
+			if path := filepath.Clean(f.Path); !listed[path] && !isManifest(path) {
+				statuses[path] = statusNew
+			}
+		}
+	}
+
+	// Print everything sorted by path, and count up anything that isn't OK.
+	paths := make([]string, 0, len(statuses))
+	for path := range statuses {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	counts := make(map[string]int)
+	w := bufio.NewWriter(os.Stdout)
+	for _, path := range paths {
+		fmt.Fprintf(w, "%s: %s\n", path, statuses[path])
+		counts[statuses[path]]++
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "hashdir: %v\n", err)
+		return 1
+	}
+
+	bad := counts[statusMismatch] + counts[statusMissing] + counts[statusNew] + failed
+	if bad == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "hashdir: %d mismatched, %d missing, %d new, %d unreadable\n",
+		counts[statusMismatch], counts[statusMissing], counts[statusNew], failed)
+	return 1
+}
+
+// readManifest loads a manifest, figuring out which format it's in by looking at
+// the first thing in the file. A JSON manifest is an array, so it starts with [.
+// Lines in a sha512sum-style manifest don't say which algorithm they are, so those
+// get defaultAlgo.
+func readManifest(path, defaultAlgo string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var entries []Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for i := range entries {
+			if entries[i].Algo == "" {
+				entries[i].Algo = defaultAlgo
+			}
+		}
+		return entries, nil
+	}
+
+	return readSumManifest(bytes.NewReader(data), path, defaultAlgo)
+}
+
+// readSumManifest parses the sha512sum format: a digest, a space, then either
+// another space (text mode) or a * (binary mode), then the path. A backslash at the
+// start of the line means the path has escapes in it (see writeSum).
+func readSumManifest(r io.Reader, name, algo string) ([]Entry, error) {
+	var entries []Entry
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		escaped := strings.HasPrefix(line, "\\")
+		if escaped {
+			line = line[1:]
+		}
+
+		digest, rest, ok := strings.Cut(line, " ")
+		if !ok || len(rest) < 2 || (rest[0] != ' ' && rest[0] != '*') {
+			return nil, fmt.Errorf("%s:%d: not a checksum line", name, lineNum)
+		}
+
+		filePath := rest[1:]
+		if escaped {
+			filePath = unescapeSumPath(filePath)
+		}
+		entries = append(entries, Entry{Path: filePath, Algo: algo, Digest: digest})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return entries, nil
+}
+
+// unescapeSumPath undoes the escaping writeSum does.
+func unescapeSumPath(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				sb.WriteByte('\n')
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSumManifestRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Path: "plain.txt", Digest: "aa"},
+		{Path: "with space.txt", Digest: "bb"},
+		{Path: "dir/nested.txt", Digest: "cc"},
+		{Path: `back\slash`, Digest: "dd"},
+		{Path: "new\nline", Digest: "ee"},
+		{Path: "both\\n\n\\", Digest: "ff"},
+		{Path: " leading space", Digest: "00"},
+		{Path: "*star", Digest: "11"},
+	}
+
+	var sb strings.Builder
+	if err := writeSum(&sb, entries); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exactly one line per entry, no matter what's in the names.
+	if lines := strings.Count(sb.String(), "\n"); lines != len(entries) {
+		t.Fatalf("writeSum wrote %d lines for %d entries:\n%s", lines, len(entries), sb.String())
+	}
+
+	got, err := readSumManifest(strings.NewReader(sb.String()), "test", "sha512")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range entries {
+		entries[i].Algo = "sha512"
+	}
+	if !slices.Equal(got, entries) {
+		t.Errorf("round trip:\n got %q\nwant %q", got, entries)
+	}
+}
+
+func TestReadSumManifest(t *testing.T) {
+	manifest := "" +
+		"aa  text.txt\n" +
+		"bb *binary.bin\n" +
+		"\n" + // blank lines are fine
+		"\\cc  esc\\\\aped\\nname\n" +
+		"\\dd *binary\\\\escaped\n"
+
+	got, err := readSumManifest(strings.NewReader(manifest), "test", "md5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Path: "text.txt", Algo: "md5", Digest: "aa"},
+		{Path: "binary.bin", Algo: "md5", Digest: "bb"},
+		{Path: "esc\\aped\nname", Algo: "md5", Digest: "cc"},
+		{Path: "binary\\escaped", Algo: "md5", Digest: "dd"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("readSumManifest:\n got %q\nwant %q", got, want)
+	}
+
+	for _, bad := range []string{"nospace\n", "aa x\n", "aa\n", "aa  \n"} {
+		if _, err := readSumManifest(strings.NewReader(bad), "test", "md5"); err == nil {
+			t.Errorf("readSumManifest(%q) accepted it", bad)
+		}
+	}
+}
+
+func TestUnescapeSumPath(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{`a\\b`, `a\b`},
+		{`a\nb`, "a\nb"},
+		{`\\n`, `\n`},
+		{`\\\n`, "\\\n"},
+		{`trailing\`, `trailing\`},
+	}
+	for _, tt := range tests {
+		if got := unescapeSumPath(tt.in); got != tt.want {
+			t.Errorf("unescapeSumPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// runVerify runs verify with stdout and stderr going to files, and returns the
+// exit status and what it printed to stdout.
+func runVerify(t *testing.T, manifestPath string, roots ...string) (int, string) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = out, out
+	status := verify(context.Background(), manifestPath, roots, "sha512", 2)
+	os.Stdout, os.Stderr = stdout, stderr
+
+	printed, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, string(printed)
+}
+
+func sha512Hex(s string) string {
+	sum := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// The obvious place to keep a manifest is right next to the files it lists. That
+// used to make the manifest itself show up as NEW, so it could never pass.
+func TestVerifyManifestInsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"a.txt": "apple", "b.txt": "banana"}
+	var entries []Entry
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, Entry{Path: path, Digest: sha512Hex(contents)})
+	}
+
+	// Manifests made with something like `find dir -type f | xargs sha512sum >
+	// dir/MANIFEST` list the manifest in itself (empty), since the shell creates
+	// it before anything gets hashed. hashdir doesn't do that any more, but those
+	// manifests should still check out.
+	manifestPath := filepath.Join(dir, "MANIFEST")
+	entries = append(entries, Entry{Path: manifestPath, Digest: sha512Hex("")})
+
+	var sb strings.Builder
+	if err := writeSum(&sb, entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifestPath, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, printed := runVerify(t, manifestPath, dir)
+	if status != 0 {
+		t.Errorf("verify = %d, want 0. It printed:\n%s", status, printed)
+	}
+	if strings.Contains(printed, "MANIFEST") {
+		t.Errorf("the manifest showed up in the results:\n%s", printed)
+	}
+
+	// And a real change still gets caught.
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("apricot"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "c.txt"), []byte("cherry"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	status, printed = runVerify(t, manifestPath, dir)
+	if status != 1 || !strings.Contains(printed, "a.txt: MISMATCH") || !strings.Contains(printed, "c.txt: NEW") {
+		t.Errorf("verify after changes = %d, printed:\n%s", status, printed)
+	}
+}
+
+// `hashdir dir > dir/MANIFEST` has to leave MANIFEST out, or the manifest lists
+// itself with the digest of an empty file and `sha512sum -c` fails on it.
+func TestHashRootsSkipsStdout(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{"a.txt": "apple", "b.txt": "banana"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifestPath := filepath.Join(dir, "MANIFEST")
+	manifest, err := os.Create(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = manifest
+	status := hashRoots(context.Background(), []string{dir}, "sha512", 2, writeSum)
+	os.Stdout = stdout
+	if err := manifest.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if status != 0 {
+		t.Fatalf("hashRoots = %d, want 0", status)
+	}
+
+	written, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(written), "MANIFEST") {
+		t.Fatalf("the manifest listed itself:\n%s", written)
+	}
+	if lines := strings.Count(string(written), "\n"); lines != 2 {
+		t.Errorf("manifest has %d lines, want 2:\n%s", lines, written)
+	}
+
+	if status, printed := runVerify(t, manifestPath, dir); status != 0 {
+		t.Errorf("verify = %d, want 0. It printed:\n%s", status, printed)
+	}
+
+	// And the real thing, if it's around.
+	sha512sum, err := exec.LookPath("sha512sum")
+	if err != nil {
+		t.Skip("no sha512sum to check against")
+	}
+	if out, err := exec.Command(sha512sum, "-c", manifestPath).CombinedOutput(); err != nil {
+		t.Errorf("sha512sum -c failed: %v\n%s", err, out)
+	}
+}
@@ -1,3 +1,10 @@
 module channels
 
-go 1.22.3
+go 1.23.0
+
+require (
+	go.uber.org/goleak v1.3.0
+	golang.org/x/crypto v0.36.0
+)
+
+require golang.org/x/sys v0.31.0 // indirect
@@ -0,0 +1,96 @@
+package hashing
+
+import (
+	"context"
+	"errors"
+	"iter"
+
+	"channels/pool"
+)
+
+// The worker in channels.go sends back a Result for every file, errors and all.
+// That's great when you want to see each one, but a lot of the time the question
+// is simpler: did it all work or not? There are two reasonable ways to answer that.
+//
+// One is to give up as soon as anything fails. There's no point hashing the other
+// 299 files if the whole job is already a failure, so we cancel the context to tell
+// every worker to stop. This is how golang.org/x/sync/errgroup works.
+//
+// The other is to keep going no matter what and hand back every error at the end.
+// errors.Join() takes a bunch of errors and rolls them into one, which still works
+// with errors.Is() and errors.As() for each of the errors inside it.
+
+// ErrorMode is how HashFiles handles a file that fails to hash.
+type ErrorMode int
+
+const (
+	// CollectAll keeps going and returns every error joined together at the end.
+	CollectAll ErrorMode = iota
+	// FailFast cancels everything that's left and returns the first error.
+	FailFast
+)
+
+// HashFiles hashes every path with the given number of workers and returns the
+// Results it got. In FailFast mode it stops at the first error, so there may be
+// fewer Results than paths. In CollectAll mode you get a Result for every path,
+// with all the errors joined together.
+func HashFiles(ctx context.Context, paths iter.Seq[string], workers int, mode ErrorMode) ([]Result, error) {
+	files := func(yield func(File) bool) {
+		for path := range paths {
+			if !yield(File{Path: path}) {
+				return
+			}
+		}
+	}
+	return hashAll(ctx, files, workers, mode)
+}
+
+// HashDir is HashFiles for every file under dir. Anything Walk can't get into
+// counts as a failure too, with its own Result, so an unreadable directory doesn't
+// just quietly hash nothing and call it a success.
+func HashDir(ctx context.Context, dir string, workers int, mode ErrorMode) ([]Result, error) {
+	return hashAll(ctx, Walk(dir), workers, mode)
+}
+
+// hashAll does the work for HashFiles and HashDir.
+func hashAll(ctx context.Context, files iter.Seq[File], workers int, mode ErrorMode) ([]Result, error) {
+	// Our own cancel func, so FailFast can stop the workers. The defer makes
+	// sure the context gets cleaned up no matter how we leave.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := pool.Run(ctx, workers, files, func(ctx context.Context, f File) (Result, error) {
+		if f.Err != nil {
+			return Result{Path: f.Path, Err: f.Err}, f.Err
+		}
+		r := hashResult(ctx, f.Path)
+		return r, r.Err
+	})
+
+	var retval []Result
+	var errs []error
+	for r := range results {
+		retval = append(retval, r.Out)
+		if r.Err == nil {
+			continue
+		}
+
+		if mode == FailFast {
+			// Cancelling is enough to get every worker to quit, so we
+			// don't have to keep reading the results channel.
+			cancel()
+			return retval, r.Err
+		}
+		errs = append(errs, r.Err)
+	}
+
+	// If the caller cancelled on us, say so, otherwise it'd look like we
+	// finished everything.
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// errors.Join returns nil if there's nothing to join, so this is nil when
+	// everything worked.
+	return retval, errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package hashing
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// makeFiles writes a file for each name in a new temp directory, with the name as
+// its contents, and returns the directory.
+func makeFiles(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func sha512Hex(s string) string {
+	sum := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestHashDir(t *testing.T) {
+	dir := makeFiles(t, "a", "b", "sub/c")
+
+	results, err := HashDir(context.Background(), dir, 2, CollectAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for _, r := range results {
+		name, _ := filepath.Rel(dir, r.Path)
+		name = filepath.ToSlash(name)
+		if r.Digest != sha512Hex(name) || r.Size != int64(len(name)) {
+			t.Errorf("%s: got %s (%d bytes), want the hash of %q", r.Path, r.Digest, r.Size, name)
+		}
+	}
+}
+
+// A directory that can't be walked has to come back as an error, not as a
+// successful hash of nothing.
+func TestHashDirWalkError(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nope")
+
+	for _, mode := range []ErrorMode{CollectAll, FailFast} {
+		results, err := HashDir(context.Background(), missing, 2, mode)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("mode %d: err = %v, want fs.ErrNotExist", mode, err)
+		}
+		if len(results) != 1 || results[0].Path != missing || results[0].Err == nil {
+			t.Errorf("mode %d: results = %+v, want one failed Result for %s", mode, results, missing)
+		}
+	}
+}
+
+func TestHashFilesModes(t *testing.T) {
+	dir := makeFiles(t, "a", "b", "c")
+	paths := []string{
+		filepath.Join(dir, "a"),
+		filepath.Join(dir, "missing1"),
+		filepath.Join(dir, "b"),
+		filepath.Join(dir, "missing2"),
+		filepath.Join(dir, "c"),
+	}
+
+	// CollectAll gets a Result for everything, and both errors.
+	results, err := HashFiles(context.Background(), slices.Values(paths), 2, CollectAll)
+	if len(results) != len(paths) {
+		t.Errorf("CollectAll: got %d results, want %d", len(results), len(paths))
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("CollectAll: err = %v, want fs.ErrNotExist", err)
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("CollectAll: want both errors joined, got %v", err)
+	}
+
+	// FailFast stops at the first one.
+	_, err = HashFiles(context.Background(), slices.Values(paths), 2, FailFast)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FailFast: err = %v, want fs.ErrNotExist", err)
+	}
+
+	// And nothing wrong means no error at all.
+	if _, err := HashFiles(context.Background(), slices.Values([]string{paths[0]}), 2, CollectAll); err != nil {
+		t.Errorf("no failures: err = %v", err)
+	}
+}
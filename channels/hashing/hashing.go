@@ -0,0 +1,74 @@
+// Package hashing is the file hashing from channels.go, pulled out so other tools
+// can import it instead of copying it. It has HashFile for one file, Walk for
+// finding every file under a directory, and HashFiles and HashDir for hashing a
+// whole bunch of them with the pool package's workers.
+package hashing
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+)
+
+// HashFile pretty much only opens the file and hashes
+// it with SHA512. It is completely channel un-aware! It also
+// hands back how many bytes it read, since io.Copy tells
+// us that for free. It does keep an eye on ctx though,
+// so a huge file doesn't keep us busy after we've been
+// told to stop.
+func HashFile(ctx context.Context, fp string) (string, int64, error) {
+	return HashFileWith(ctx, fp, sha512.New)
+}
+
+// HashFileWith is HashFile with whatever hash newHash makes instead of SHA512, like
+// sha256.New or md5.New. Anything that returns a hash.Hash works.
+func HashFileWith(ctx context.Context, fp string, newHash func() hash.Hash) (string, int64, error) {
+	thisFile, err := os.Open(fp)
+	if err != nil {
+		return "", 0, err
+	}
+	// always defer file closure!
+	defer thisFile.Close()
+
+	h := newHash()
+	size, err := io.Copy(h, ctxReader{ctx: ctx, r: thisFile})
+	if err != nil {
+		return "", size, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// Result is what a worker sends back for each file. If hashing failed, Err says
+// why, and the caller gets to decide what to do about it.
+type Result struct {
+	Path   string
+	Digest string
+	Size   int64
+	Err    error
+}
+
+// hashResult runs HashFile and packs everything into a Result.
+func hashResult(ctx context.Context, path string) Result {
+	digest, size, err := HashFile(ctx, path)
+	return Result{Path: path, Digest: digest, Size: size, Err: err}
+}
+
+// io.Copy reads a chunk at a time (32KB), and it has no idea what a context is. But
+// it'll call Read on whatever we give it, so if we wrap the file in something that
+// checks the context before every Read, the copy stops within one chunk of being
+// cancelled instead of grinding through a 50GB file first.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,133 @@
+package hashing
+
+import (
+	"context"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// endless is a reader that never runs out, and cancels the context after a
+// certain number of reads.
+type endless struct {
+	reads       int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (e *endless) Read(p []byte) (int, error) {
+	e.reads++
+	if e.reads == e.cancelAfter {
+		e.cancel()
+	}
+	clear(p)
+	return len(p), nil
+}
+
+// Without ctxReader this io.Copy would never end. With it, the copy stops on the
+// very next Read after the cancel.
+func TestCtxReaderStopsCopy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	src := &endless{cancelAfter: 100, cancel: cancel}
+
+	n, err := io.Copy(sha512.New(), ctxReader{ctx: ctx, r: src})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("io.Copy = %v, want context.Canceled", err)
+	}
+	if src.reads != 100 {
+		t.Errorf("kept reading after the cancel: %d reads, want 100", src.reads)
+	}
+	if n == 0 {
+		t.Error("io.Copy didn't copy anything before the cancel")
+	}
+}
+
+// bigFile makes a 1GB file. It's sparse, so it doesn't really take up the space,
+// but it still takes a good second or two to hash.
+func bigFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "big")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.Truncate(1 << 30); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHashFileCancel(t *testing.T) {
+	path := bigFile(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, size, err := HashFile(ctx, path)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("HashFile = %v, want context.Canceled", err)
+	}
+	if size >= 1<<30 {
+		t.Error("HashFile read the whole file anyway")
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("HashFile took %v to notice the cancel", took)
+	}
+}
+
+// Cancel HashFiles partway through an input that never ends. It has to come back
+// with the cancel error, and every goroutine it started has to be gone.
+func TestHashFilesCancel(t *testing.T) {
+	dir := makeFiles(t, "a")
+	path := filepath.Join(dir, "a")
+	same := func(yield func(string) bool) {
+		for yield(path) {
+		}
+	}
+
+	for _, mode := range []ErrorMode{CollectAll, FailFast} {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := HashFiles(ctx, same, 4, mode)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("mode %d: err = %v, want context.Canceled", mode, err)
+		}
+
+		goleak.VerifyNone(t)
+	}
+}
+
+// FailFast walks away from the results channel as soon as something fails, with
+// the workers still busy on the rest. That can't leave any of them behind either.
+func TestHashFilesFailFastLeavesNothingBehind(t *testing.T) {
+	dir := makeFiles(t, "a")
+	good := filepath.Join(dir, "a")
+	bad := filepath.Join(dir, "missing")
+	paths := func(yield func(string) bool) {
+		for i := 0; ; i++ {
+			p := good
+			if i == 20 {
+				p = bad
+			}
+			if !yield(p) {
+				return
+			}
+		}
+	}
+
+	_, err := HashFiles(context.Background(), paths, 4, FailFast)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want os.ErrNotExist", err)
+	}
+	goleak.VerifyNone(t)
+}
@@ -0,0 +1,66 @@
+package hashing
+
+import (
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// File is one thing Walk came across. Usually that's a file to hash, but if Walk
+// couldn't read something (a directory without permission, say), Err says why. The
+// error rides along with everything else instead of getting printed and forgotten,
+// so whoever is hashing can report it the same way as a file that failed to hash.
+type File struct {
+	Path string
+	Err  error
+}
+
+// Walk turns filepath.WalkDir into an iterator over every file under each of the
+// roots, so it can be handed to pool.Run. Giving it a file instead of a directory
+// just yields that file, since that's what filepath.WalkDir does.
+func Walk(roots ...string) iter.Seq[File] {
+	return func(yield func(File) bool) {
+		for _, root := range roots {
+			// Returning filepath.SkipAll is how we tell WalkDir to quit early
+			// when yield says to stop. But WalkDir swallows SkipAll and just
+			// returns nil, so we can't tell afterwards whether it finished or
+			// got stopped. Hence the flag. Without it we'd go right on to the
+			// next root and call yield again, and Go panics if you do that
+			// after a range loop has already been broken out of.
+			stopped := false
+			send := func(f File) error {
+				if !yield(f) {
+					stopped = true
+					return filepath.SkipAll
+				}
+				return nil
+			}
+
+			filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					// Hand it over and keep going with everything else.
+					return send(File{Path: path, Err: err})
+				}
+
+				if d.IsDir() {
+					return nil
+				}
+
+				// WalkDir doesn't follow symlinks, so a link to a directory
+				// shows up as a non-directory. Check what it points at.
+				if d.Type()&fs.ModeSymlink != 0 {
+					if info, err := os.Stat(path); err == nil && info.IsDir() {
+						return nil
+					}
+				}
+
+				return send(File{Path: path})
+			})
+
+			if stopped {
+				return
+			}
+		}
+	}
+}
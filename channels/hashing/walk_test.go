@@ -0,0 +1,66 @@
+package hashing
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	d1 := makeFiles(t, "a", "sub/b")
+	d2 := makeFiles(t, "c")
+	missing := filepath.Join(t.TempDir(), "nope")
+	single := filepath.Join(d2, "c")
+
+	// A link to a directory shouldn't show up as a file.
+	if err := os.Symlink(filepath.Join(d1, "sub"), filepath.Join(d2, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	var failed []string
+	for f := range Walk(d1, missing, d2, single) {
+		if f.Err != nil {
+			failed = append(failed, f.Path)
+			continue
+		}
+		paths = append(paths, f.Path)
+	}
+
+	want := []string{
+		filepath.Join(d1, "a"),
+		filepath.Join(d1, "sub", "b"),
+		filepath.Join(d2, "c"),
+		single,
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("Walk found %v, want %v", paths, want)
+	}
+	if !slices.Equal(failed, []string{missing}) {
+		t.Errorf("Walk failed on %v, want just %s", failed, missing)
+	}
+}
+
+// Breaking out of the loop has to stop the whole walk, not just the root it's on.
+// This used to carry on to the next root and call yield again, which panics with
+// "range function continued iteration after function for loop body returned false".
+func TestWalkBreak(t *testing.T) {
+	d1 := makeFiles(t, "a", "b")
+	d2 := makeFiles(t, "c", "d")
+	d3 := makeFiles(t, "e")
+	missing := filepath.Join(t.TempDir(), "nope")
+
+	for stopAfter := 1; stopAfter <= 6; stopAfter++ {
+		seen := 0
+		for range Walk(d1, missing, d2, d3) {
+			seen++
+			if seen == stopAfter {
+				break
+			}
+		}
+		if seen != stopAfter {
+			t.Errorf("wanted to stop after %d, got %d", stopAfter, seen)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package pool is the worker pool from channels.go, pulled out so it can be
+// imported instead of copied and pasted into every tool that needs one.
+package pool
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// The pattern in channels.go goes like this: one goroutine feeds work into a
+// channel, a handful of workers pull from that channel and push what they come up
+// with into a results channel, and one more goroutine waits on a WaitGroup so it
+// can close the results channel once every worker is done. That's the exact same
+// every time except for two things: what the work is, and what the worker does with
+// it. So those are what you pass in here, and the plumbing is done for you.
+//
+// The other thing that's different from channels.go is that results come back as a
+// Result struct instead of a map with one thing in it, and errors come back with
+// them instead of getting printed and thrown away. What to do about an error is the
+// caller's call to make.
+
+// Result is what one piece of work turned into. In is the input it came from, so
+// you can tell which is which, since results come back in whatever order the
+// workers finish.
+type Result[In, Out any] struct {
+	In  In
+	Out Out
+	Err error
+}
+
+// Option changes how Run sets things up.
+type Option func(*config)
+
+type config struct {
+	inBuffer  int
+	outBuffer int
+}
+
+// WithInputBuffer sets how many inputs can be waiting in line for a worker. The
+// default is the number of workers.
+func WithInputBuffer(n int) Option {
+	return func(c *config) {
+		c.inBuffer = n
+	}
+}
+
+// WithOutputBuffer sets how many results can pile up before the workers have to
+// wait for you to read them. The default is the number of workers.
+func WithOutputBuffer(n int) Option {
+	return func(c *config) {
+		c.outBuffer = n
+	}
+}
+
+// Run starts n workers that call fn on every item from in, and returns a channel
+// that gets every Result. The channel is closed once everything is done, so you can
+// just range over it.
+//
+// If ctx is cancelled, Run stops handing out new work, the workers quit, and the
+// results channel gets closed. You need to either read every result or cancel ctx,
+// though. If you just walk away from the channel, the workers will sit there
+// forever waiting for you to take their results.
+func Run[In, Out any](ctx context.Context, n int, in iter.Seq[In], fn func(context.Context, In) (Out, error), opts ...Option) <-chan Result[In, Out] {
+	if n < 1 {
+		n = 1
+	}
+
+	cfg := config{inBuffer: n, outBuffer: n}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	jobs := make(chan In, max(cfg.inBuffer, 0))
+	results := make(chan Result[In, Out], max(cfg.outBuffer, 0))
+
+	// This is the producer, same as the filepath.WalkDir goroutine in channels.go.
+	// The select means we stop trying to hand out work as soon as ctx is
+	// cancelled, instead of blocking on a channel nobody is reading anymore.
+	go func() {
+		defer close(jobs)
+		for item := range in {
+			select {
+			case jobs <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Now the workers.
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for range n {
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				// Don't bother starting something new if we've been told to stop.
+				if ctx.Err() != nil {
+					return
+				}
+
+				out, err := fn(ctx, item)
+				select {
+				case results <- Result[In, Out]{In: item, Out: out, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// And the closer, which waits for every worker to finish.
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// forever hands out 0, 1, 2, ... until whoever is ranging over it stops.
+func forever(yield func(int) bool) {
+	for i := 0; ; i++ {
+		if !yield(i) {
+			return
+		}
+	}
+}
+
+func double(ctx context.Context, i int) (int, error) {
+	return 2 * i, nil
+}
+
+func TestRun(t *testing.T) {
+
+	in := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	var got []int
+	for r := range Run(context.Background(), 3, slices.Values(in), double) {
+		if r.Err != nil || r.Out != 2*r.In {
+			t.Errorf("Result %+v", r)
+		}
+		got = append(got, r.In)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, in) {
+		t.Errorf("got results for %v, want %v", got, in)
+	}
+
+	goleak.VerifyNone(t)
+}
+
+func TestRunErrors(t *testing.T) {
+	boom := errors.New("boom")
+	results := Run(context.Background(), 2, slices.Values([]int{1, 2, 3}), func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			return 0, boom
+		}
+		return i, nil
+	})
+
+	failed := 0
+	for r := range results {
+		if r.Err != nil {
+			if r.In != 2 || !errors.Is(r.Err, boom) {
+				t.Errorf("unexpected error Result %+v", r)
+			}
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("%d failed Results, want 1", failed)
+	}
+}
+
+// The leak this whole thing is about: take a few results, then cancel and walk
+// away without reading the rest. The producer is still trying to hand out work from
+// an endless input, and the workers are all stuck trying to send results nobody is
+// reading. Every one of them has to notice the cancel and quit.
+func TestRunCancelLeavesNothingBehind(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithInputBuffer(0), WithOutputBuffer(0)},
+		{WithInputBuffer(100), WithOutputBuffer(100)},
+	} {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		results := Run(ctx, 8, forever, double, opts...)
+		for range 5 {
+			<-results
+		}
+		cancel()
+
+		goleak.VerifyNone(t)
+	}
+}
+
+// Cancelling has to close the results channel too, so a range over it ends.
+func TestRunCancelClosesResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results := Run(ctx, 4, forever, double)
+	<-results
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		for range results {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("results channel never closed after cancel")
+	}
+}
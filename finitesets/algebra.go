@@ -0,0 +1,101 @@
+package finitesets
+
+// These are the operations that make sets worth having in the first place. In
+// Python you'd write a | b, a & b, a - b and a ^ b. Go doesn't let you overload
+// operators, so here they're just methods that return a brand new set and leave
+// both of the originals alone.
+//
+// A lot of these only need to look at one of the two sets and check the other one
+// for membership. Checking membership is cheap no matter how big the set is, but
+// looping over a set isn't, so wherever the answer doesn't care which one we loop
+// over, we loop over the smaller one.
+
+// smallerFirst returns the two sets with the smaller one first.
+func smallerFirst[T comparable](a, b Set[T]) (Set[T], Set[T]) {
+	if len(a) <= len(b) {
+		return a, b
+	}
+	return b, a
+}
+
+// Union returns everything that's in either set (Python's a | b).
+func (s Set[T]) Union(other Set[T]) Set[T] {
+	// Start from a copy of the bigger one, so there's less to add afterwards.
+	small, big := smallerFirst(s, other)
+	retval := big.Clone()
+	for item := range small {
+		retval.Add(item)
+	}
+	return retval
+}
+
+// Intersection returns only what's in both sets (Python's a & b).
+func (s Set[T]) Intersection(other Set[T]) Set[T] {
+	small, big := smallerFirst(s, other)
+	retval := New[T]()
+	for item := range small {
+		if big.Contains(item) {
+			retval.Add(item)
+		}
+	}
+	return retval
+}
+
+// Difference returns what's in s but not in other (Python's a - b). This one does
+// care about the order, so we always have to loop over s.
+func (s Set[T]) Difference(other Set[T]) Set[T] {
+	retval := New[T]()
+	for item := range s {
+		if !other.Contains(item) {
+			retval.Add(item)
+		}
+	}
+	return retval
+}
+
+// SymmetricDifference returns what's in one set or the other, but not both
+// (Python's a ^ b).
+func (s Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	retval := s.Difference(other)
+	for item := range other {
+		if !s.Contains(item) {
+			retval.Add(item)
+		}
+	}
+	return retval
+}
+
+// IsSubset reports whether everything in s is also in other (Python's a <= b).
+func (s Set[T]) IsSubset(other Set[T]) bool {
+	// A bigger set can't possibly fit inside a smaller one.
+	if len(s) > len(other) {
+		return false
+	}
+	for item := range s {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether everything in other is also in s (Python's a >= b).
+func (s Set[T]) IsSuperset(other Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint reports whether the two sets have nothing at all in common.
+func (s Set[T]) IsDisjoint(other Set[T]) bool {
+	small, big := smallerFirst(s, other)
+	for item := range small {
+		if big.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both sets have exactly the same items.
+func (s Set[T]) Equal(other Set[T]) bool {
+	return len(s) == len(other) && s.IsSubset(other)
+}
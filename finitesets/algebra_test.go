@@ -0,0 +1,118 @@
+package finitesets
+
+import "testing"
+
+func TestAlgebra(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+
+	tests := []struct {
+		name string
+		got  Set[int]
+		want Set[int]
+	}{
+		{"Union", a.Union(b), New(1, 2, 3, 4, 5)},
+		{"Intersection", a.Intersection(b), New(3, 4)},
+		{"Difference", a.Difference(b), New(1, 2)},
+		{"Difference the other way", b.Difference(a), New(5)},
+		{"SymmetricDifference", a.SymmetricDifference(b), New(1, 2, 5)},
+		{"Union with empty", a.Union(New[int]()), a},
+		{"Intersection with empty", a.Intersection(New[int]()), New[int]()},
+		{"Union with nil", Set[int](nil).Union(b), b},
+	}
+
+	for _, tt := range tests {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// None of those should have touched the originals.
+	if !a.Equal(New(1, 2, 3, 4)) || !b.Equal(New(3, 4, 5)) {
+		t.Errorf("originals changed: a = %v, b = %v", a, b)
+	}
+}
+
+func TestAlgebraPredicates(t *testing.T) {
+	small := New("a", "b")
+	big := New("a", "b", "c")
+	other := New("x", "y")
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"small.IsSubset(big)", small.IsSubset(big), true},
+		{"big.IsSubset(small)", big.IsSubset(small), false},
+		{"small.IsSubset(small)", small.IsSubset(small), true},
+		{"big.IsSuperset(small)", big.IsSuperset(small), true},
+		{"small.IsSuperset(big)", small.IsSuperset(big), false},
+		{"big.IsDisjoint(other)", big.IsDisjoint(other), true},
+		{"big.IsDisjoint(small)", big.IsDisjoint(small), false},
+		{"empty.IsSubset(small)", New[string]().IsSubset(small), true},
+		{"small.Equal(big)", small.Equal(big), false},
+		{"small.Equal(clone)", small.Equal(small.Clone()), true},
+		// same size, different items
+		{"small.Equal(other)", small.Equal(other), false},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// Two big overlapping squares of Coords, one 500x500 and one 300x300 sitting
+// over its corner.
+func benchCoordSets() (Set[Coord], Set[Coord]) {
+	a, b := New[Coord](), New[Coord]()
+	for x := range 500 {
+		for y := range 500 {
+			a.Add(Coord{x, y})
+		}
+	}
+	for x := 400; x < 700; x++ {
+		for y := 400; y < 700; y++ {
+			b.Add(Coord{x, y})
+		}
+	}
+	return a, b
+}
+
+func BenchmarkUnion(b *testing.B) {
+	s1, s2 := benchCoordSets()
+	for b.Loop() {
+		s1.Union(s2)
+	}
+}
+
+func BenchmarkIntersection(b *testing.B) {
+	s1, s2 := benchCoordSets()
+	for b.Loop() {
+		s1.Intersection(s2)
+	}
+}
+
+func BenchmarkDifference(b *testing.B) {
+	s1, s2 := benchCoordSets()
+	for b.Loop() {
+		s1.Difference(s2)
+	}
+}
+
+func BenchmarkSymmetricDifference(b *testing.B) {
+	s1, s2 := benchCoordSets()
+	for b.Loop() {
+		s1.SymmetricDifference(s2)
+	}
+}
+
+func BenchmarkIsSubset(b *testing.B) {
+	s1, _ := benchCoordSets()
+	s2 := s1.Clone()
+	for b.Loop() {
+		s1.IsSubset(s2)
+	}
+}
@@ -0,0 +1,177 @@
+package finitesets
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+
+	"mergesort"
+)
+
+// A set only cares whether something is in it or not. A lot of the time you also
+// want to know _how many times_ it showed up, like counting words in a file. That's
+// a multiset, or a "bag", and it's what Python calls a Counter. Same trick as Set,
+// except instead of an empty struct the value is how many of that item we've seen.
+//
+// One rule keeps everything simple: nothing in a Bag ever has a count of zero or
+// less. If a count drops that low, the item is just removed.
+
+// Bag counts how many of each item it's holding.
+type Bag[T comparable] map[T]int
+
+// Counted is an item and how many times it's in a Bag.
+type Counted[T comparable] struct {
+	Item  T
+	Count int
+}
+
+// NewBag makes a Bag and adds one of each item you pass in, so passing the same
+// thing twice gives it a count of two. Handy for counting things like the words
+// that come out of SplitStrings in the filesandstrings module:
+//
+//	words := finitesets.NewBag(strings.Split(text, " ")...)
+func NewBag[T comparable](items ...T) Bag[T] {
+	b := make(Bag[T], len(items))
+	for _, item := range items {
+		b.Add(item, 1)
+	}
+	return b
+}
+
+// Add adds n of item to the bag. A negative n takes them away instead, and if the
+// count ends up at zero or below, the item is removed.
+func (b Bag[T]) Add(item T, n int) {
+	count := b[item] + n
+	if count <= 0 {
+		delete(b, item)
+		return
+	}
+	b[item] = count
+}
+
+// Remove takes n of item out of the bag. It's the same as Add with -n.
+func (b Bag[T]) Remove(item T, n int) {
+	b.Add(item, -n)
+}
+
+// Count is how many of item are in the bag. Reading a missing key from a map gives
+// you the zero value, so anything that isn't in the bag comes back as 0 for free.
+func (b Bag[T]) Count(item T) int {
+	return b[item]
+}
+
+// Contains reports whether there's at least one of item in the bag.
+func (b Bag[T]) Contains(item T) bool {
+	return b[item] > 0
+}
+
+// Len is how many _different_ items are in the bag.
+func (b Bag[T]) Len() int {
+	return len(b)
+}
+
+// Total adds up every count in the bag.
+func (b Bag[T]) Total() int {
+	total := 0
+	for _, count := range b {
+		total += count
+	}
+	return total
+}
+
+// Clone makes a copy of the bag.
+func (b Bag[T]) Clone() Bag[T] {
+	if b == nil {
+		return NewBag[T]()
+	}
+	return maps.Clone(b)
+}
+
+// All ranges over every item and its count, in no particular order.
+//
+//	for word, count := range words.All() {
+//		fmt.Printf("%s: %d\n", word, count)
+//	}
+func (b Bag[T]) All() iter.Seq2[T, int] {
+	return maps.All(b)
+}
+
+// Set returns the items in the bag without their counts.
+func (b Bag[T]) Set() Set[T] {
+	retval := make(Set[T], len(b))
+	for item := range b {
+		retval.Add(item)
+	}
+	return retval
+}
+
+// MostCommon returns the k items with the highest counts, biggest first, just like
+// Python's Counter.most_common(). If k is zero or less, you get everything. Items
+// with the same count come out in no particular order.
+func (b Bag[T]) MostCommon(k int) []Counted[T] {
+	all := make([]Counted[T], 0, len(b))
+	for item, count := range b {
+		all = append(all, Counted[T]{Item: item, Count: count})
+	}
+
+	sorted := mergesort.MergeSortFunc(all, func(x, y Counted[T]) int {
+		// y before x, so the biggest count ends up first
+		return cmp.Compare(y.Count, x.Count)
+	})
+
+	if k > 0 && k < len(sorted) {
+		sorted = sorted[:k]
+	}
+	return sorted
+}
+
+// Just like Set, these all return a brand new Bag and leave the originals alone.
+// They work the same way Python's Counter operators do.
+
+// Sum adds the counts from both bags together (Python's a + b).
+func (b Bag[T]) Sum(other Bag[T]) Bag[T] {
+	retval := b.Clone()
+	for item, count := range other {
+		retval.Add(item, count)
+	}
+	return retval
+}
+
+// Union keeps the higher count of each item out of the two bags (Python's a | b).
+func (b Bag[T]) Union(other Bag[T]) Bag[T] {
+	retval := b.Clone()
+	for item, count := range other {
+		if count > retval[item] {
+			retval[item] = count
+		}
+	}
+	return retval
+}
+
+// Intersect keeps only items in both bags, with the lower of the two counts
+// (Python's a & b).
+func (b Bag[T]) Intersect(other Bag[T]) Bag[T] {
+	// Just like Set.Intersection, only loop over the smaller one.
+	small, big := b, other
+	if len(small) > len(big) {
+		small, big = big, small
+	}
+
+	retval := NewBag[T]()
+	for item, count := range small {
+		if n := min(count, big[item]); n > 0 {
+			retval[item] = n
+		}
+	}
+	return retval
+}
+
+// Subtract takes other's counts away from b's, and drops anything that ends up at
+// zero or less (Python's a - b).
+func (b Bag[T]) Subtract(other Bag[T]) Bag[T] {
+	retval := b.Clone()
+	for item, count := range other {
+		retval.Remove(item, count)
+	}
+	return retval
+}
@@ -0,0 +1,123 @@
+package finitesets
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestBagAdd(t *testing.T) {
+	b := NewBag("a", "b", "a")
+	if b.Count("a") != 2 || b.Count("b") != 1 || b.Count("z") != 0 {
+		t.Fatalf("NewBag counts = %v", b)
+	}
+
+	b.Add("a", 3)
+	if b.Count("a") != 5 || b.Total() != 6 {
+		t.Errorf("after Add(a, 3): %v, Total = %d", b, b.Total())
+	}
+
+	// A negative n takes some away, and once it hits zero (or goes past it)
+	// the item is gone entirely, not sitting there with a count of 0.
+	b.Add("a", -4)
+	if b.Count("a") != 1 {
+		t.Errorf("after Add(a, -4), Count(a) = %d, want 1", b.Count("a"))
+	}
+	b.Add("a", -1)
+	if _, ok := b["a"]; ok || b.Contains("a") {
+		t.Errorf("a is still in the bag at zero: %v", b)
+	}
+	b.Remove("b", 10)
+	if _, ok := b["b"]; ok || b.Len() != 0 {
+		t.Errorf("b is still in the bag below zero: %v", b)
+	}
+
+	// Taking away something that was never there doesn't leave a negative count.
+	b.Add("c", -2)
+	if _, ok := b["c"]; ok {
+		t.Errorf("c got a count of %d", b["c"])
+	}
+}
+
+func TestBagAlgebra(t *testing.T) {
+	a := Bag[string]{"x": 3, "y": 1, "only a": 2}
+	b := Bag[string]{"x": 1, "y": 4, "only b": 5}
+
+	tests := []struct {
+		name string
+		got  Bag[string]
+		want Bag[string]
+	}{
+		{"Sum", a.Sum(b), Bag[string]{"x": 4, "y": 5, "only a": 2, "only b": 5}},
+		{"Union", a.Union(b), Bag[string]{"x": 3, "y": 4, "only a": 2, "only b": 5}},
+		{"Intersect", a.Intersect(b), Bag[string]{"x": 1, "y": 1}},
+		{"Intersect the other way", b.Intersect(a), Bag[string]{"x": 1, "y": 1}},
+		// y would be -3, so it's dropped along with anything only in b.
+		{"Subtract", a.Subtract(b), Bag[string]{"x": 2, "only a": 2}},
+		{"Subtract the other way", b.Subtract(a), Bag[string]{"y": 3, "only b": 5}},
+		{"Subtract itself", a.Subtract(a), Bag[string]{}},
+		{"Sum with nil", a.Sum(nil), a},
+		{"nil Union", Bag[string](nil).Union(b), b},
+	}
+
+	for _, tt := range tests {
+		if !maps.Equal(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// None of that should have touched the originals.
+	if !maps.Equal(a, Bag[string]{"x": 3, "y": 1, "only a": 2}) || !maps.Equal(b, Bag[string]{"x": 1, "y": 4, "only b": 5}) {
+		t.Errorf("originals changed: %v, %v", a, b)
+	}
+}
+
+func TestBagMostCommon(t *testing.T) {
+	b := Bag[string]{"a": 1, "b": 5, "c": 3, "d": 4}
+
+	tests := []struct {
+		k    int
+		want []string
+	}{
+		{1, []string{"b"}},
+		{2, []string{"b", "d"}},
+		{4, []string{"b", "d", "c", "a"}},
+		// More than there are just gives you all of them, and so does k <= 0.
+		{10, []string{"b", "d", "c", "a"}},
+		{0, []string{"b", "d", "c", "a"}},
+		{-1, []string{"b", "d", "c", "a"}},
+	}
+
+	for _, tt := range tests {
+		got := b.MostCommon(tt.k)
+		if len(got) != len(tt.want) {
+			t.Errorf("MostCommon(%d) = %v, want %v", tt.k, got, tt.want)
+			continue
+		}
+		for i, c := range got {
+			if c.Item != tt.want[i] || c.Count != b[c.Item] {
+				t.Errorf("MostCommon(%d) = %v, want %v", tt.k, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if got := NewBag[int]().MostCommon(3); len(got) != 0 {
+		t.Errorf("MostCommon of an empty bag = %v", got)
+	}
+}
+
+// The example from the README.
+func TestBagReadme(t *testing.T) {
+	words := NewBag(strings.Split("the cat and the hat", " ")...)
+	if words.Count("the") != 2 {
+		t.Errorf("Count(the) = %d, want 2", words.Count("the"))
+	}
+	words.Add("cat", 3)
+	if words.Count("cat") != 4 {
+		t.Errorf("cat is %d, want 4", words.Count("cat"))
+	}
+	if got := words.MostCommon(1); len(got) != 1 || got[0] != (Counted[string]{"cat", 4}) {
+		t.Errorf("MostCommon(1) = %v, want [{cat 4}]", got)
+	}
+}
@@ -0,0 +1,180 @@
+package finitesets
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// The empty struct trick saves a byte per item over using bools, but a map still
+// costs a whole lot more than that per item (the key, the hashing bookkeeping, all
+// the empty space a map keeps around so it doesn't have to grow all the time). If
+// you're storing a bunch of small, non-negative ints that are fairly close together,
+// you can do WAY better with a bitset: one bit per possible number, on if it's in the
+// set and off if it isn't. A million numbers fit in 125KB.
+//
+// The bits are packed into uint64 "words", so number i lives in word i/64, at bit
+// i%64. The nice part is that operations like union and intersection become one OR
+// or AND per word, which does 64 numbers at once.
+
+// wordSize is how many bits fit in each word.
+const wordSize = 64
+
+// BitSet is a set of non-negative ints, stored as one bit per number. It only makes
+// sense when the numbers are reasonably small, since it has to have room for every
+// number from 0 up to the biggest one in the set.
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet makes a BitSet and adds any numbers you pass in.
+func NewBitSet(items ...int) *BitSet {
+	b := &BitSet{}
+	for _, item := range items {
+		b.Add(item)
+	}
+	return b
+}
+
+// Add puts i in the set, growing it if it needs to. There's no bit for negative
+// numbers, so adding one panics, just like indexing a slice with one would.
+func (b *BitSet) Add(i int) {
+	if i < 0 {
+		panic(fmt.Sprintf("finitesets: BitSet can't hold negative number %d", i))
+	}
+
+	word := i / wordSize
+	if word >= len(b.words) {
+		// grow to fit, keeping whatever we had before
+		b.words = append(b.words, make([]uint64, word-len(b.words)+1)...)
+	}
+	b.words[word] |= 1 << (i % wordSize)
+}
+
+// Remove takes i out of the set.
+func (b *BitSet) Remove(i int) {
+	if i < 0 || i/wordSize >= len(b.words) {
+		return
+	}
+	b.words[i/wordSize] &^= 1 << (i % wordSize)
+}
+
+// Contains reports whether i is in the set.
+func (b *BitSet) Contains(i int) bool {
+	if i < 0 || i/wordSize >= len(b.words) {
+		return false
+	}
+	return b.words[i/wordSize]&(1<<(i%wordSize)) != 0
+}
+
+// Len is how many numbers are in the set. Rather than checking every bit, this
+// uses bits.OnesCount64 (a "popcount"), which most CPUs can do in one instruction.
+func (b *BitSet) Len() int {
+	total := 0
+	for _, w := range b.words {
+		total += bits.OnesCount64(w)
+	}
+	return total
+}
+
+// Clear empties the set out.
+func (b *BitSet) Clear() {
+	b.words = nil
+}
+
+// Clone makes a copy of the set.
+func (b *BitSet) Clone() *BitSet {
+	return &BitSet{words: append([]uint64(nil), b.words...)}
+}
+
+// All ranges over every number in the set, smallest first. Unlike Set, the order
+// is always the same since the bits are in order to begin with.
+func (b *BitSet) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for wi, w := range b.words {
+			// Instead of checking all 64 bits, jump straight to the next one
+			// that's set with TrailingZeros64, then clear it and go again.
+			for w != 0 {
+				bit := bits.TrailingZeros64(w)
+				if !yield(wi*wordSize + bit) {
+					return
+				}
+				w &= w - 1 // clears the lowest bit that's set
+			}
+		}
+	}
+}
+
+// Union returns every number in either set.
+func (b *BitSet) Union(other *BitSet) *BitSet {
+	// Start with a copy of whichever is longer, then OR in the shorter one.
+	long, short := b.words, other.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+
+	retval := &BitSet{words: append([]uint64(nil), long...)}
+	for i, w := range short {
+		retval.words[i] |= w
+	}
+	return retval
+}
+
+// Intersection returns the numbers in both sets.
+func (b *BitSet) Intersection(other *BitSet) *BitSet {
+	n := min(len(b.words), len(other.words))
+	retval := &BitSet{words: make([]uint64, n)}
+	for i := range n {
+		retval.words[i] = b.words[i] & other.words[i]
+	}
+	return retval.trim()
+}
+
+// Difference returns the numbers in b that aren't in other.
+func (b *BitSet) Difference(other *BitSet) *BitSet {
+	retval := b.Clone()
+	for i := range min(len(b.words), len(other.words)) {
+		retval.words[i] &^= other.words[i]
+	}
+	return retval.trim()
+}
+
+// SymmetricDifference returns the numbers in one set or the other, but not both.
+func (b *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	retval := b.Union(other)
+	for i := range min(len(b.words), len(other.words)) {
+		retval.words[i] = b.words[i] ^ other.words[i]
+	}
+	return retval.trim()
+}
+
+// IsSubset reports whether every number in b is also in other.
+func (b *BitSet) IsSubset(other *BitSet) bool {
+	for i, w := range b.words {
+		var o uint64
+		if i < len(other.words) {
+			o = other.words[i]
+		}
+		// Any bit that's on in w but off in o means it's not a subset.
+		if w&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both sets have exactly the same numbers.
+func (b *BitSet) Equal(other *BitSet) bool {
+	return b.IsSubset(other) && other.IsSubset(b)
+}
+
+// trim chops off any empty words at the end so the set doesn't hang on to memory
+// it isn't using.
+func (b *BitSet) trim() *BitSet {
+	n := len(b.words)
+	for n > 0 && b.words[n-1] == 0 {
+		n--
+	}
+	b.words = b.words[:n]
+	return b
+}
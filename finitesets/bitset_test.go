@@ -0,0 +1,150 @@
+package finitesets
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// BitSet should always agree with a plain Set[int] doing the same things.
+func TestBitSetMatchesSet(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for range 100 {
+		a, b := NewBitSet(), NewBitSet()
+		sa, sb := New[int](), New[int]()
+		for range 200 {
+			// Keep them different sizes so the algebra has to deal with one
+			// having more words than the other.
+			x, y := r.Intn(300), r.Intn(150)
+			a.Add(x)
+			sa.Add(x)
+			b.Add(y)
+			sb.Add(y)
+			if r.Intn(4) == 0 {
+				a.Remove(y)
+				sa.Remove(y)
+			}
+		}
+
+		check := func(name string, got *BitSet, want Set[int]) {
+			t.Helper()
+			if got.Len() != want.Len() {
+				t.Fatalf("%s: Len() = %d, want %d", name, got.Len(), want.Len())
+			}
+			items := slices.Collect(got.All())
+			if !slices.IsSorted(items) {
+				t.Fatalf("%s: All() isn't in order: %v", name, items)
+			}
+			if !New(items...).Equal(want) {
+				t.Fatalf("%s: got %v, want %v", name, items, want)
+			}
+		}
+
+		check("a", a, sa)
+		check("Union", a.Union(b), sa.Union(sb))
+		check("Intersection", a.Intersection(b), sa.Intersection(sb))
+		check("Difference", a.Difference(b), sa.Difference(sb))
+		check("SymmetricDifference", a.SymmetricDifference(b), sa.SymmetricDifference(sb))
+
+		if a.IsSubset(b) != sa.IsSubset(sb) {
+			t.Fatalf("IsSubset = %v, want %v", a.IsSubset(b), sa.IsSubset(sb))
+		}
+		if !a.Union(b).Intersection(a).Equal(a) {
+			t.Fatal("(a | b) & a should equal a")
+		}
+	}
+}
+
+func TestBitSetNegative(t *testing.T) {
+	b := NewBitSet(1, 2)
+	if b.Contains(-1) {
+		t.Error("Contains(-1) = true")
+	}
+	b.Remove(-1) // should just do nothing
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Add(-1) didn't panic")
+		}
+	}()
+	b.Add(-1)
+}
+
+// The dense case BitSet is made for: the numbers 0 through 99,999. Run with
+// -benchmem to see the memory side of it.
+const benchBits = 100_000
+
+func BenchmarkBitSetAdd(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		s := NewBitSet()
+		for i := range benchBits {
+			s.Add(i)
+		}
+	}
+}
+
+func BenchmarkMapSetAdd(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		s := map[int]struct{}{}
+		for i := range benchBits {
+			s[i] = struct{}{}
+		}
+	}
+}
+
+func BenchmarkBitSetContains(b *testing.B) {
+	s := NewBitSet()
+	for i := 0; i < benchBits; i += 2 {
+		s.Add(i)
+	}
+	for b.Loop() {
+		for i := range benchBits {
+			s.Contains(i)
+		}
+	}
+}
+
+func BenchmarkMapSetContains(b *testing.B) {
+	s := map[int]struct{}{}
+	for i := 0; i < benchBits; i += 2 {
+		s[i] = struct{}{}
+	}
+	for b.Loop() {
+		for i := range benchBits {
+			_ = s[i]
+		}
+	}
+}
+
+func BenchmarkBitSetUnion(b *testing.B) {
+	s1, s2 := NewBitSet(), NewBitSet()
+	for i := range benchBits {
+		if i%2 == 0 {
+			s1.Add(i)
+		} else {
+			s2.Add(i)
+		}
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		s1.Union(s2)
+	}
+}
+
+func BenchmarkMapSetUnion(b *testing.B) {
+	s1, s2 := New[int](), New[int]()
+	for i := range benchBits {
+		if i%2 == 0 {
+			s1.Add(i)
+		} else {
+			s2.Add(i)
+		}
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		s1.Union(s2)
+	}
+}
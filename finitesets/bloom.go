@@ -0,0 +1,189 @@
+package finitesets
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Sometimes there's just too much stuff to keep an exact set of it in memory, like
+// millions of file hashes. If you can live with being wrong _sometimes_, there are
+// data structures that use a tiny fraction of the memory. A Bloom filter answers
+// "have I seen this before?" with either "definitely not" or "probably". It never
+// says no to something you added, but it'll occasionally say yes to something you
+// didn't, and you get to pick how occasionally.
+//
+// It works like this: there's a big BitSet, and every key gets hashed k different
+// ways, each picking one bit. Adding a key turns those k bits on. Checking a key
+// looks at its k bits, and if any of them are off, it was never added. If they're
+// all on, either it was added or other keys just happened to turn on all the same
+// bits. The more bits and hashes per key, the less likely that gets.
+
+// BloomFilter is a probabilistic set of []byte or string keys.
+type BloomFilter struct {
+	bits   *BitSet
+	m      uint64 // how many bits there are
+	hashes uint64 // how many bits each key turns on
+}
+
+// These are the biggest filter UnmarshalBinary will believe. Data from disk (or the
+// network) can say anything it wants, and without a limit a header claiming 2^64
+// bits makes the word count math overflow. 2^40 bits is a 128GB filter, and nobody
+// needs anywhere near 64 hashes per key (1 in a billion false positives only takes
+// about 30).
+const (
+	maxBloomBits   = 1 << 40
+	maxBloomHashes = 64
+)
+
+// ErrIncompatible is returned when trying to merge two filters (or estimators) that
+// weren't set up the same way.
+var ErrIncompatible = errors.New("finitesets: can't merge, sizes don't match")
+
+// NewBloomFilter makes a Bloom filter sized to hold expected keys while wrongly
+// saying "probably" to roughly fpRate of the keys it's never seen (0.01 means 1%).
+// Adding a lot more than expected keys still works, but fpRate goes up.
+func NewBloomFilter(expected int, fpRate float64) *BloomFilter {
+	if expected < 1 {
+		expected = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+
+	// These are the standard formulas for picking the best number of bits and
+	// hashes. Look up "Bloom filter optimal k" if you want the derivation.
+	n := float64(expected)
+	m := math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / n * math.Ln2)
+
+	return newBloomFilter(uint64(m), uint64(max(k, 1)))
+}
+
+func newBloomFilter(m, hashes uint64) *BloomFilter {
+	return &BloomFilter{
+		bits:   &BitSet{words: make([]uint64, (m+wordSize-1)/wordSize)},
+		m:      m,
+		hashes: hashes,
+	}
+}
+
+// Add puts key in the filter.
+func (f *BloomFilter) Add(key []byte) {
+	h1, h2 := hashKey(key)
+	for i := range f.hashes {
+		f.bits.Add(int(f.bit(h1, h2, i)))
+	}
+}
+
+// AddString is Add for strings.
+func (f *BloomFilter) AddString(key string) {
+	f.Add([]byte(key))
+}
+
+// Contains reports whether key might be in the filter. false means it definitely
+// isn't, true means it probably is.
+func (f *BloomFilter) Contains(key []byte) bool {
+	h1, h2 := hashKey(key)
+	for i := range f.hashes {
+		if !f.bits.Contains(int(f.bit(h1, h2, i))) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsString is Contains for strings.
+func (f *BloomFilter) ContainsString(key string) bool {
+	return f.Contains([]byte(key))
+}
+
+// bit picks the i'th bit for a key. Rather than actually hashing the key k times,
+// we combine two hashes as h1 + i*h2, which is known to work just as well.
+func (f *BloomFilter) bit(h1, h2, i uint64) uint64 {
+	return (h1 + i*h2) % f.m
+}
+
+// Merge adds everything from other into f, as if every key added to other had been
+// added to f too. This is how you combine filters built by separate workers. They
+// have to have been made with the same settings.
+func (f *BloomFilter) Merge(other *BloomFilter) error {
+	if f.m != other.m || f.hashes != other.hashes {
+		return ErrIncompatible
+	}
+	// Since a key just turns bits on, merging is just OR'ing the bits together.
+	f.bits = f.bits.Union(other.bits)
+	return nil
+}
+
+// MarshalBinary saves the filter so it can be written to disk. The format is the
+// number of bits, the number of hashes, and then the bits themselves, all as
+// big-endian uint64s.
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	words := (f.m + wordSize - 1) / wordSize
+	retval := make([]byte, 0, 8*(2+words))
+	retval = binary.BigEndian.AppendUint64(retval, f.m)
+	retval = binary.BigEndian.AppendUint64(retval, f.hashes)
+	for i := range words {
+		var w uint64
+		if i < uint64(len(f.bits.words)) {
+			w = f.bits.words[i]
+		}
+		retval = binary.BigEndian.AppendUint64(retval, w)
+	}
+	return retval, nil
+}
+
+// UnmarshalBinary loads a filter saved with MarshalBinary.
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("finitesets: bloom filter data too short (%d bytes)", len(data))
+	}
+	m := binary.BigEndian.Uint64(data[0:8])
+	hashes := binary.BigEndian.Uint64(data[8:16])
+	data = data[16:]
+
+	// Check the sizes are sane BEFORE doing any math with them.
+	if m == 0 || m > maxBloomBits || hashes == 0 || hashes > maxBloomHashes {
+		return fmt.Errorf("finitesets: bloom filter data is corrupt")
+	}
+	words := (m + wordSize - 1) / wordSize
+	if uint64(len(data)) != 8*words {
+		return fmt.Errorf("finitesets: bloom filter data is corrupt")
+	}
+
+	*f = *newBloomFilter(m, hashes)
+	for i := range f.bits.words {
+		f.bits.words[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	return nil
+}
+
+// hashKey hashes a key into two 64 bit numbers. We can't use hash/maphash like
+// ShardedSet does, because it's randomly seeded every time the program starts, and
+// a filter saved to disk has to hash keys the same way when it's loaded back up.
+// FNV is stable, but its bits aren't very well mixed, so each half goes through a
+// mixing step afterwards.
+func hashKey(key []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(key)
+	sum := h.Sum(nil)
+
+	h1 := mix64(binary.BigEndian.Uint64(sum[0:8]))
+	h2 := mix64(binary.BigEndian.Uint64(sum[8:16]))
+	// h2 is a step size, so it can't be zero or every hash would be the same bit.
+	return h1, h2 | 1
+}
+
+// mix64 scrambles the bits of x so that every input bit affects every output bit.
+// This is the finalizer from the SplitMix64 random number generator.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
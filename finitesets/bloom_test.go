@@ -0,0 +1,129 @@
+package finitesets
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// key makes the i'th test key. Keys from different prefixes never overlap, so
+// "seen" and "unseen" keys can't collide by accident.
+func key(prefix string, i int) string {
+	return fmt.Sprintf("%s-%d", prefix, i)
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	const n = 100_000
+
+	for _, fpRate := range []float64{0.1, 0.01, 0.001} {
+		f := NewBloomFilter(n, fpRate)
+		for i := range n {
+			f.AddString(key("seen", i))
+		}
+
+		// A Bloom filter never forgets anything.
+		for i := range n {
+			if !f.ContainsString(key("seen", i)) {
+				t.Fatalf("fpRate %v: key %d was added but Contains says no", fpRate, i)
+			}
+		}
+
+		// And it should only say yes to about fpRate of the keys it hasn't seen.
+		// With this many keys the observed rate should be well within half of
+		// the target either way.
+		falsePositives := 0
+		for i := range n {
+			if f.ContainsString(key("unseen", i)) {
+				falsePositives++
+			}
+		}
+		observed := float64(falsePositives) / n
+		if observed < fpRate/2 || observed > fpRate*1.5 {
+			t.Errorf("fpRate %v: observed false positive rate %v", fpRate, observed)
+		}
+	}
+}
+
+func TestBloomFilterMerge(t *testing.T) {
+	a := NewBloomFilter(1000, 0.01)
+	b := NewBloomFilter(1000, 0.01)
+	for i := range 500 {
+		a.AddString(key("a", i))
+		b.AddString(key("b", i))
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 500 {
+		if !a.ContainsString(key("a", i)) || !a.ContainsString(key("b", i)) {
+			t.Fatalf("merged filter is missing key %d", i)
+		}
+	}
+
+	if err := a.Merge(NewBloomFilter(5000, 0.01)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Merge with a different size = %v, want ErrIncompatible", err)
+	}
+}
+
+func TestBloomFilterMarshal(t *testing.T) {
+	f := NewBloomFilter(1000, 0.01)
+	for i := range 1000 {
+		f.AddString(key("seen", i))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g BloomFilter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every answer should be the same after the round trip, seen or not.
+	for i := range 1000 {
+		for _, k := range []string{key("seen", i), key("unseen", i)} {
+			if f.ContainsString(k) != g.ContainsString(k) {
+				t.Fatalf("Contains(%q) changed after a round trip", k)
+			}
+		}
+	}
+
+	again, _ := g.MarshalBinary()
+	if string(again) != string(data) {
+		t.Error("marshalling a second time gave different bytes")
+	}
+}
+
+func TestBloomFilterUnmarshalCorrupt(t *testing.T) {
+	header := func(m, hashes uint64, words int) []byte {
+		data := binary.BigEndian.AppendUint64(nil, m)
+		data = binary.BigEndian.AppendUint64(data, hashes)
+		return append(data, make([]byte, 8*words)...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"too short", make([]byte, 10)},
+		{"no bits", header(0, 3, 0)},
+		{"no hashes", header(64, 0, 1)},
+		{"too many hashes", header(64, math.MaxUint64, 1)},
+		{"wrong length", header(128, 3, 1)},
+		// This one used to overflow the word count to zero and get accepted.
+		{"2^64 bits", header(math.MaxUint64, 3, 0)},
+		{"way too many bits", header(1<<62, 3, 0)},
+	}
+
+	for _, tt := range tests {
+		var f BloomFilter
+		if err := f.UnmarshalBinary(tt.data); err == nil {
+			t.Errorf("%s: UnmarshalBinary accepted it", tt.name)
+		}
+	}
+}
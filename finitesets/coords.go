@@ -0,0 +1,235 @@
+package finitesets
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"mergesort"
+)
+
+// A set of Coords is basically a map of a grid where we only write down the squares
+// that are "on". That's great for grid puzzles and tile maps, since a huge, mostly
+// empty grid only costs as much memory as the squares that are actually filled in.
+// CoordSet is a Set[Coord] with some grid-specific stuff added on.
+//
+// Y goes down as rows and X goes across as columns, like text on a screen, so
+// Coord{0, 0} is the top left corner when it gets rendered.
+
+// MaxRenderCells is the biggest grid Render will draw, in squares. That's a 4096 by
+// 4096 picture, or 16MB of text, which is already way past readable.
+const MaxRenderCells = 1 << 24
+
+// Connectivity is which squares count as touching.
+type Connectivity int
+
+const (
+	// Four means only up, down, left and right are neighbours.
+	Four Connectivity = 4
+	// Eight adds the diagonals too.
+	Eight Connectivity = 8
+)
+
+// These are how far away each neighbour is. The first four are the straight ones,
+// and the diagonals come after, so Four just uses the front of the list.
+var neighborOffsets = [8]Coord{
+	{0, -1}, {-1, 0}, {1, 0}, {0, 1},
+	{-1, -1}, {1, -1}, {-1, 1}, {1, 1},
+}
+
+// Neighbors returns every coordinate touching c, whether it's in a set or not.
+func (c Coord) Neighbors(conn Connectivity) []Coord {
+	offsets := neighborOffsets[:4]
+	if conn == Eight {
+		offsets = neighborOffsets[:]
+	}
+
+	retval := make([]Coord, 0, len(offsets))
+	for _, o := range offsets {
+		retval = append(retval, Coord{X: c.X + o.X, Y: c.Y + o.Y})
+	}
+	return retval
+}
+
+// CoordSet is a set of grid coordinates. Since it embeds Set[Coord], all of Set's
+// methods (Add, Contains, Union and so on) work on it too.
+type CoordSet struct {
+	Set[Coord]
+}
+
+// NewCoordSet makes a CoordSet and adds any coordinates you pass in.
+func NewCoordSet(coords ...Coord) CoordSet {
+	return CoordSet{New(coords...)}
+}
+
+// Bounds returns the top left and bottom right corners of the smallest rectangle
+// that fits everything in the set. ok is false if the set is empty, since there
+// isn't a rectangle then.
+func (s CoordSet) Bounds() (minC, maxC Coord, ok bool) {
+	first := true
+	for c := range s.Set {
+		if first {
+			minC, maxC = c, c
+			first = false
+			continue
+		}
+		minC.X = min(minC.X, c.X)
+		minC.Y = min(minC.Y, c.Y)
+		maxC.X = max(maxC.X, c.X)
+		maxC.Y = max(maxC.Y, c.Y)
+	}
+	return minC, maxC, !first
+}
+
+// Neighbors returns the coordinates touching c that are in the set.
+func (s CoordSet) Neighbors(c Coord, conn Connectivity) []Coord {
+	var retval []Coord
+	for _, n := range c.Neighbors(conn) {
+		if s.Contains(n) {
+			retval = append(retval, n)
+		}
+	}
+	return retval
+}
+
+// FloodFill returns every coordinate in the set that you can get to from start by
+// stepping between neighbours, like the paint bucket tool. If start isn't in the
+// set, you get back an empty set.
+func (s CoordSet) FloodFill(start Coord, conn Connectivity) CoordSet {
+	filled := NewCoordSet()
+	if !s.Contains(start) {
+		return filled
+	}
+
+	// This is a plain old breadth-first search. The queue holds the squares we
+	// still need to look around, and the filled set doubles as the list of
+	// places we've already been so we don't go in circles.
+	filled.Add(start)
+	queue := []Coord{start}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		for _, n := range s.Neighbors(c, conn) {
+			if !filled.Contains(n) {
+				filled.Add(n)
+				queue = append(queue, n)
+			}
+		}
+	}
+	return filled
+}
+
+// Components splits the set up into groups that touch each other (islands, if
+// you're doing one of those puzzles). They come back ordered by whichever one has
+// the first coordinate reading top to bottom, left to right.
+func (s CoordSet) Components(conn Connectivity) []CoordSet {
+	var retval []CoordSet
+	seen := NewCoordSet()
+
+	// Go through the coordinates in reading order so the result is the same
+	// every time. Ranging over the map directly would shuffle it.
+	for _, c := range s.sorted() {
+		if seen.Contains(c) {
+			continue
+		}
+		component := s.FloodFill(c, conn)
+		for member := range component.Set {
+			seen.Add(member)
+		}
+		retval = append(retval, component)
+	}
+	return retval
+}
+
+// InRect returns the coordinates in the set that fall inside the rectangle from
+// corner a to corner b, edges included. The corners can be given in any order.
+func (s CoordSet) InRect(a, b Coord) CoordSet {
+	minC := Coord{X: min(a.X, b.X), Y: min(a.Y, b.Y)}
+	maxC := Coord{X: max(a.X, b.X), Y: max(a.Y, b.Y)}
+
+	retval := NewCoordSet()
+
+	// We can either check every square in the rectangle against the set, or
+	// every item in the set against the rectangle. Whichever is smaller wins.
+	// Careful though, a rectangle from math.MinInt to math.MaxInt is wider
+	// than an int can count, so the area gets worked out with uint64s, and
+	// bits.Mul64 tells us if even that overflowed.
+	w, h := span(minC.X, maxC.X), span(minC.Y, maxC.Y)
+	hi, area := bits.Mul64(w+1, h+1)
+	if w+1 != 0 && h+1 != 0 && hi == 0 && area < uint64(s.Len()) {
+		for dy := range h + 1 {
+			for dx := range w + 1 {
+				c := Coord{X: minC.X + int(dx), Y: minC.Y + int(dy)}
+				if s.Contains(c) {
+					retval.Add(c)
+				}
+			}
+		}
+		return retval
+	}
+
+	for c := range s.Set {
+		if c.X >= minC.X && c.X <= maxC.X && c.Y >= minC.Y && c.Y <= maxC.Y {
+			retval.Add(c)
+		}
+	}
+	return retval
+}
+
+// Render draws the set as a grid of text, one line per row, just big enough to fit
+// everything. Squares in the set are drawn with filled and everything else with
+// empty. Super handy for figuring out why a puzzle answer is wrong.
+//
+//	fmt.Print(s.Render('#', '.'))
+//
+// Two squares a long way apart make a huge picture though, and {0, 0} plus
+// {1 << 40, 0} would be a terabyte of text. So if the grid would have more than
+// MaxRenderCells squares in it, Render doesn't draw it and just returns a one line
+// note saying how big it is instead.
+func (s CoordSet) Render(filled, empty rune) string {
+	minC, maxC, ok := s.Bounds()
+	if !ok {
+		return ""
+	}
+
+	// Same overflow-proof area check as InRect.
+	w, h := span(minC.X, maxC.X), span(minC.Y, maxC.Y)
+	hi, area := bits.Mul64(w+1, h+1)
+	if w+1 == 0 || h+1 == 0 || hi != 0 || area > MaxRenderCells {
+		return fmt.Sprintf("(%d squares from %v to %v, too big to render)\n", s.Len(), minC, maxC)
+	}
+
+	var sb strings.Builder
+	sb.Grow(int(area + h + 1))
+	for dy := uint64(0); dy <= h; dy++ {
+		for dx := uint64(0); dx <= w; dx++ {
+			if s.Contains(Coord{X: minC.X + int(dx), Y: minC.Y + int(dy)}) {
+				sb.WriteRune(filled)
+			} else {
+				sb.WriteRune(empty)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// span is how many steps it takes to get from lo up to hi. Doing it in uint64 means
+// it can't overflow, even from math.MinInt all the way to math.MaxInt. Adding it
+// back onto lo as an int wraps around to exactly the right answer, too.
+func span(lo, hi int) uint64 {
+	return uint64(hi) - uint64(lo)
+}
+
+// sorted returns the coordinates in reading order: top to bottom, then left to right.
+func (s CoordSet) sorted() []Coord {
+	coords := make([]Coord, 0, s.Len())
+	for c := range s.Set {
+		coords = append(coords, c)
+	}
+
+	byRow := mergesort.By(func(c Coord) int { return c.Y }).
+		Then(mergesort.By(func(c Coord) int { return c.X }))
+	return mergesort.MergeSortFunc(coords, byRow)
+}
@@ -0,0 +1,211 @@
+package finitesets
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// parseGrid turns a picture like the ones Render draws back into a CoordSet, with
+// '#' for squares that are in the set. Much easier to read than a pile of Coords.
+func parseGrid(rows ...string) CoordSet {
+	s := NewCoordSet()
+	for y, row := range rows {
+		for x, r := range row {
+			if r == '#' {
+				s.Add(Coord{X: x, Y: y})
+			}
+		}
+	}
+	return s
+}
+
+func TestBounds(t *testing.T) {
+	if _, _, ok := NewCoordSet().Bounds(); ok {
+		t.Error("Bounds() of an empty set should not be ok")
+	}
+
+	minC, maxC, ok := NewCoordSet(Coord{3, 4}).Bounds()
+	if !ok || minC != (Coord{3, 4}) || maxC != (Coord{3, 4}) {
+		t.Errorf("Bounds() of one square = %v, %v, %v", minC, maxC, ok)
+	}
+
+	minC, maxC, ok = NewCoordSet(Coord{-2, 5}, Coord{7, -1}, Coord{0, 0}).Bounds()
+	if !ok || minC != (Coord{-2, -1}) || maxC != (Coord{7, 5}) {
+		t.Errorf("Bounds() = %v, %v, %v, want {-2 -1}, {7 5}, true", minC, maxC, ok)
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := parseGrid(
+		"#..#",
+		".##.",
+		"....",
+		"#...",
+	)
+	want := "#..#\n.##.\n....\n#...\n"
+	if got := s.Render('#', '.'); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	// It only draws as much as it needs, so shifting everything over doesn't
+	// change the picture.
+	shifted := NewCoordSet()
+	for c := range s.Set {
+		shifted.Add(Coord{X: c.X - 100, Y: c.Y + 50})
+	}
+	if got := shifted.Render('#', '.'); got != want {
+		t.Errorf("Render() of shifted set =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := NewCoordSet().Render('#', '.'); got != "" {
+		t.Errorf("Render() of an empty set = %q, want nothing", got)
+	}
+}
+
+// Squares a long way apart would make a picture way too big to hold, so these get
+// a short note instead. The first two would overflow the area, and the full width
+// one even overflows w+1.
+func TestRenderTooBig(t *testing.T) {
+	tests := []struct {
+		name string
+		s    CoordSet
+		want string
+	}{
+		{"far apart", NewCoordSet(Coord{0, 0}, Coord{1 << 40, 0}), "(2 squares from {0 0} to {1099511627776 0}, too big to render)\n"},
+		{"full width", NewCoordSet(Coord{math.MinInt, 0}, Coord{math.MaxInt, 0}), fmt.Sprintf("(2 squares from {%d 0} to {%d 0}, too big to render)\n", math.MinInt, math.MaxInt)},
+		{"full everything", NewCoordSet(Coord{math.MinInt, math.MinInt}, Coord{math.MaxInt, math.MaxInt}), fmt.Sprintf("(2 squares from {%d %d} to {%d %d}, too big to render)\n", math.MinInt, math.MinInt, math.MaxInt, math.MaxInt)},
+		{"one over", NewCoordSet(Coord{0, 0}, Coord{4096, 4095}), "(2 squares from {0 0} to {4096 4095}, too big to render)\n"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Render('#', '.'); got != tt.want {
+			t.Errorf("%s: Render() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Right at the limit still gets drawn.
+	got := NewCoordSet(Coord{0, 0}, Coord{4095, 4095}).Render('#', '.')
+	if len(got) != MaxRenderCells+4096 {
+		t.Errorf("Render() at the limit is %d bytes, want %d", len(got), MaxRenderCells+4096)
+	}
+}
+
+func TestComponents(t *testing.T) {
+	s := parseGrid(
+		"##..#",
+		"#...#",
+		"..#..",
+		"...##",
+	)
+
+	// With four-way connectivity the diagonal doesn't count, so the square in
+	// the middle is on its own.
+	four := s.Components(Four)
+	wantFour := []string{
+		"##\n#.\n",
+		"#\n#\n",
+		"#\n",
+		"##\n",
+	}
+	if len(four) != len(wantFour) {
+		t.Fatalf("Components(Four) found %d, want %d", len(four), len(wantFour))
+	}
+	for i, c := range four {
+		if got := c.Render('#', '.'); got != wantFour[i] {
+			t.Errorf("Components(Four)[%d] =\n%s\nwant\n%s", i, got, wantFour[i])
+		}
+	}
+
+	// With eight, the middle square joins up with the piece at the bottom.
+	eight := s.Components(Eight)
+	wantEight := []string{
+		"##\n#.\n",
+		"#\n#\n",
+		"#..\n.##\n",
+	}
+	if len(eight) != len(wantEight) {
+		t.Fatalf("Components(Eight) found %d, want %d", len(eight), len(wantEight))
+	}
+	for i, c := range eight {
+		if got := c.Render('#', '.'); got != wantEight[i] {
+			t.Errorf("Components(Eight)[%d] =\n%s\nwant\n%s", i, got, wantEight[i])
+		}
+	}
+
+	if got := NewCoordSet().Components(Four); len(got) != 0 {
+		t.Errorf("Components() of an empty set = %v", got)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	s := parseGrid(
+		"#.#",
+		"###",
+	)
+	if got := s.FloodFill(Coord{0, 0}, Four); !got.Equal(s.Set) {
+		t.Errorf("FloodFill() = %v, want everything", got)
+	}
+	if got := s.FloodFill(Coord{1, 0}, Four); got.Len() != 0 {
+		t.Errorf("FloodFill() from outside the set = %v, want nothing", got)
+	}
+}
+
+func TestInRect(t *testing.T) {
+	s := parseGrid(
+		"####",
+		"####",
+		"####",
+	)
+
+	tests := []struct {
+		name string
+		a, b Coord
+		want int
+	}{
+		// small enough that it scans the rectangle
+		{"one square", Coord{1, 1}, Coord{1, 1}, 1},
+		{"corners backwards", Coord{2, 2}, Coord{1, 1}, 4},
+		// big enough that it scans the set instead
+		{"everything", Coord{-10, -10}, Coord{10, 10}, 12},
+		{"nothing", Coord{20, 20}, Coord{30, 30}, 0},
+		// These used to overflow the area calculation and loop forever.
+		{"full width", Coord{math.MinInt, 0}, Coord{math.MaxInt, 10}, 12},
+		{"full everything", Coord{math.MinInt, math.MinInt}, Coord{math.MaxInt, math.MaxInt}, 12},
+		{"full height", Coord{0, math.MinInt}, Coord{0, math.MaxInt}, 3},
+	}
+
+	for _, tt := range tests {
+		got := s.InRect(tt.a, tt.b)
+		if got.Len() != tt.want {
+			t.Errorf("%s: InRect(%v, %v) has %d, want %d", tt.name, tt.a, tt.b, got.Len(), tt.want)
+		}
+		for c := range got.Set {
+			if !s.Contains(c) {
+				t.Errorf("%s: InRect returned %v, which isn't in the set", tt.name, c)
+			}
+		}
+	}
+}
+
+// A small rectangle right up against math.MaxInt still takes the scanning path,
+// and x++ would wrap around there if the loop wasn't careful.
+func TestInRectAtTheEdge(t *testing.T) {
+	s := NewCoordSet(
+		Coord{math.MaxInt, math.MaxInt},
+		Coord{math.MaxInt - 1, math.MaxInt},
+		Coord{math.MinInt, math.MinInt},
+	)
+	for x := range 10 {
+		s.Add(Coord{x, 0})
+	}
+
+	got := s.InRect(Coord{math.MaxInt - 1, math.MaxInt}, Coord{math.MaxInt, math.MaxInt})
+	if got.Len() != 2 {
+		t.Errorf("InRect at math.MaxInt = %v, want 2 squares", got)
+	}
+
+	got = s.InRect(Coord{math.MinInt, math.MinInt}, Coord{math.MinInt, math.MinInt})
+	if got.Len() != 1 {
+		t.Errorf("InRect at math.MinInt = %v, want 1 square", got)
+	}
+}
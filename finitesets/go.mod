@@ -1,3 +1,7 @@
 module finitesets
 
-go 1.22.3
+go 1.24
+
+require mergesort v0.0.0
+
+replace mergesort => ../mergesort
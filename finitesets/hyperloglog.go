@@ -0,0 +1,138 @@
+package finitesets
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// A Bloom filter tells you if you've seen something. A HyperLogLog tells you how
+// many _different_ things you've seen, without remembering any of them. It can
+// count billions of unique items in a few kilobytes, give or take a couple percent.
+//
+// The idea is kind of wild. Hash every item, and look at how many zeros the hash
+// starts with. Half of all hashes start with a 1, a quarter start with 01, an eighth
+// with 001 and so on. So if the most leading zeros you've ever seen is 20, you've
+// probably seen around 2^20 different things. That guess is really noisy on its own,
+// so the hash also picks one of a bunch of "registers", each keeps its own guess,
+// and they get averaged together at the end.
+
+// HyperLogLog estimates how many distinct keys have been added to it.
+type HyperLogLog struct {
+	precision uint8
+	registers []uint8
+}
+
+// NewHyperLogLog makes an estimator with 2^precision registers, so precision can
+// be from 4 to 16. More registers use more memory (one byte each) but give a
+// better estimate: the typical error is about 1.04/sqrt(2^precision), so 14 (16KB)
+// is within about 0.8%. Anything out of range gets clamped.
+func NewHyperLogLog(precision int) *HyperLogLog {
+	precision = min(max(precision, 4), 16)
+	return &HyperLogLog{
+		precision: uint8(precision),
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add counts key. Adding the same key again doesn't change anything.
+func (h *HyperLogLog) Add(key []byte) {
+	x, _ := hashKey(key)
+
+	// The top bits of the hash pick the register...
+	idx := x >> (64 - h.precision)
+
+	// ...and the rest of it is what we count the leading zeros of. Shifting the
+	// register bits off the top leaves zeros on the bottom, so OR a 1 in right
+	// after where the real bits end to stop the count from running past them.
+	rest := x<<h.precision | 1<<(h.precision-1)
+	rank := uint8(bits.LeadingZeros64(rest)) + 1
+
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// AddString is Add for strings.
+func (h *HyperLogLog) AddString(key string) {
+	h.Add([]byte(key))
+}
+
+// Count estimates how many distinct keys have been added.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+
+	// Average the registers together with a harmonic mean, which keeps one
+	// unusually big register from throwing everything off.
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(m) * m * m / sum
+
+	// When there aren't many keys yet, a lot of registers are still empty and the
+	// estimate above is pretty bad. Counting the empty registers works better then
+	// (this is called "linear counting").
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(math.Round(estimate))
+}
+
+// alpha is a correction factor from the HyperLogLog paper that depends on how many
+// registers there are.
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/m)
+	}
+}
+
+// Merge combines other into h, so the count covers keys added to either one. This
+// is how you combine estimators from separate workers. They need the same precision.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.precision != other.precision {
+		return ErrIncompatible
+	}
+	// Each register holds the biggest rank it's seen, so the merged register is
+	// just the bigger of the two.
+	for i, r := range other.registers {
+		h.registers[i] = max(h.registers[i], r)
+	}
+	return nil
+}
+
+// MarshalBinary saves the estimator so it can be written to disk. It's just the
+// precision followed by every register, one byte each.
+func (h *HyperLogLog) MarshalBinary() ([]byte, error) {
+	retval := make([]byte, 0, 1+len(h.registers))
+	retval = append(retval, h.precision)
+	retval = append(retval, h.registers...)
+	return retval, nil
+}
+
+// UnmarshalBinary loads an estimator saved with MarshalBinary.
+func (h *HyperLogLog) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return fmt.Errorf("finitesets: hyperloglog data is empty")
+	}
+	precision := data[0]
+	if precision < 4 || precision > 16 || len(data)-1 != 1<<precision {
+		return fmt.Errorf("finitesets: hyperloglog data is corrupt")
+	}
+
+	h.precision = precision
+	h.registers = append([]uint8(nil), data[1:]...)
+	return nil
+}
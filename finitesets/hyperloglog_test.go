@@ -0,0 +1,98 @@
+package finitesets
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestHyperLogLogError(t *testing.T) {
+	for _, precision := range []int{10, 12, 14} {
+		// The typical (one standard deviation) error is 1.04/sqrt(m). Allow
+		// three of those, which it should basically never miss by.
+		m := float64(int(1) << precision)
+		allowed := 3 * 1.04 / math.Sqrt(m)
+
+		h := NewHyperLogLog(precision)
+		added := 0
+		for _, n := range []int{100, 1_000, 10_000, 100_000, 1_000_000} {
+			for ; added < n; added++ {
+				h.AddString(key("hll", added))
+			}
+
+			got := h.Count()
+			relErr := math.Abs(float64(got)-float64(n)) / float64(n)
+			if relErr > allowed {
+				t.Errorf("precision %d, %d keys: Count() = %d, off by %.2f%% (allowed %.2f%%)",
+					precision, n, got, 100*relErr, 100*allowed)
+			}
+		}
+	}
+}
+
+func TestHyperLogLogDuplicates(t *testing.T) {
+	h := NewHyperLogLog(14)
+	for range 10 {
+		for i := range 1000 {
+			h.AddString(key("dup", i))
+		}
+	}
+	if got := h.Count(); got < 970 || got > 1030 {
+		t.Errorf("1000 keys added 10 times each: Count() = %d", got)
+	}
+
+	if got := NewHyperLogLog(14).Count(); got != 0 {
+		t.Errorf("empty Count() = %d, want 0", got)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, b, all := NewHyperLogLog(12), NewHyperLogLog(12), NewHyperLogLog(12)
+	for i := range 50_000 {
+		a.AddString(key("a", i))
+		b.AddString(key("b", i))
+		all.AddString(key("a", i))
+		all.AddString(key("b", i))
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	// Merging has to give exactly the same registers as adding everything to one
+	// estimator, so the counts should match exactly.
+	if a.Count() != all.Count() {
+		t.Errorf("merged Count() = %d, want %d", a.Count(), all.Count())
+	}
+
+	if err := a.Merge(NewHyperLogLog(10)); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Merge with a different precision = %v, want ErrIncompatible", err)
+	}
+}
+
+func TestHyperLogLogMarshal(t *testing.T) {
+	h := NewHyperLogLog(12)
+	for i := range 10_000 {
+		h.AddString(key("hll", i))
+	}
+
+	data, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g HyperLogLog
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if g.Count() != h.Count() {
+		t.Errorf("Count() after a round trip = %d, want %d", g.Count(), h.Count())
+	}
+
+	// and it keeps working afterwards
+	g.AddString("one more")
+
+	for _, bad := range [][]byte{nil, {3}, {17}, {12, 0, 0}} {
+		if err := g.UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary(%v) accepted it", bad)
+		}
+	}
+}
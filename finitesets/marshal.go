@@ -0,0 +1,168 @@
+package finitesets
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// Since a Set is a map[T]struct{}, if you hand one straight to json.Marshal you get
+// something like {"50":{},"100":{}}. That's pretty useless, and it doesn't even work
+// when T is a struct like Coord, because JSON object keys have to be strings. What
+// you'd actually expect a set to look like is a list, so these methods make a Set
+// marshal to (and unmarshal from) a JSON array instead.
+//
+// Sets don't have an order, but it's really annoying when the same set comes out
+// different every time you save it. So the array is sorted. We don't know what T
+// is at compile time, but reflect can tell us at run time. If it's made of things
+// that have an obvious order (numbers, strings, bools, and structs or arrays of
+// those, like Coord), the items get sorted by their actual values, field by field
+// for structs. Anything else (pointers, interfaces and so on) doesn't have a
+// natural order, so for those we encode each item first and sort by the JSON text,
+// which is at least the same every time.
+//
+// How each item is encoded is up to T, so field tags work just like they do in the
+// marshalling module. That's why Coord has them.
+
+// MarshalJSON makes a Set encode as a sorted JSON array.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	items := make([]T, 0, len(s))
+	for item := range s {
+		items = append(items, item)
+	}
+	sorted := orderable(reflect.TypeFor[T]())
+	if sorted {
+		slices.SortFunc(items, func(a, b T) int {
+			return compareValues(reflect.ValueOf(a), reflect.ValueOf(b))
+		})
+	}
+
+	encoded := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		var err error
+		if encoded[i], err = json.Marshal(item); err != nil {
+			return nil, err
+		}
+	}
+
+	if !sorted {
+		slices.SortFunc(encoded, func(a, b json.RawMessage) int {
+			return bytes.Compare(a, b)
+		})
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON reads a JSON array into the set. Whatever was in the set before is
+// replaced, and any duplicates in the array just collapse into one item, like
+// you'd expect. Note the pointer receiver, since we may have to make the map.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = New(items...)
+	return nil
+}
+
+// MarshalText makes Set satisfy encoding.TextMarshaler, which some other encoders
+// (and things like flag parsing) look for. The text is the same sorted JSON array.
+func (s Set[T]) MarshalText() ([]byte, error) {
+	return s.MarshalJSON()
+}
+
+// UnmarshalText is the other half of MarshalText.
+func (s *Set[T]) UnmarshalText(text []byte) error {
+	return s.UnmarshalJSON(text)
+}
+
+// gob has the same problem as JSON, except worse. It flat out refuses to encode
+// struct{} since it has no exported fields. So for gob we just send the items as a
+// plain slice and build the set back up on the other end.
+
+// GobEncode makes Set satisfy gob.GobEncoder.
+func (s Set[T]) GobEncode() ([]byte, error) {
+	items := make([]T, 0, len(s))
+	for item := range s {
+		items = append(items, item)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(items); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode makes *Set satisfy gob.GobDecoder.
+func (s *Set[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	*s = New(items...)
+	return nil
+}
+
+// orderable reports whether compareValues knows how to order values of type t.
+func orderable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array:
+		return orderable(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !orderable(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compareValues orders two values of a type that orderable said yes to. Structs
+// and arrays are compared one field or element at a time, and the first one that
+// differs decides it.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Bool:
+		// false before true
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Array:
+		for i := range a.Len() {
+			if c := compareValues(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if c := compareValues(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,141 @@
+package finitesets
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalJSONOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		got  func() ([]byte, error)
+		want string
+	}{
+		{"ints", New(100, 50, -3, 9001).MarshalJSON, `[-3,50,100,9001]`},
+		{"floats", New(2.5, -1.0, 10.0).MarshalJSON, `[-1,2.5,10]`},
+		// These come out in a different order if you sort by the encoded
+		// text, since json.Marshal turns < into \u003c and " into \".
+		{"strings", New("A", "<").MarshalJSON, `["\u003c","A"]`},
+		{"quotes", New(`a"b`, "a#").MarshalJSON, `["a\"b","a#"]`},
+		{"coords", New(Coord{10, 0}, Coord{2, 5}, Coord{2, -1}).MarshalJSON, `[{"x":2,"y":-1},{"x":2,"y":5},{"x":10,"y":0}]`},
+		{"bools", New(true, false).MarshalJSON, `[false,true]`},
+		{"empty", New[int]().MarshalJSON, `[]`},
+		// Pointers don't have an order, so they fall back to the JSON text.
+		{"pointers", New(ptr(3), ptr(1), ptr(2)).MarshalJSON, `[1,2,3]`},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.got()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestJSONRoundTrip(t *testing.T) {
+	ints := New(1, 2, 3, 50, 100)
+	data, err := json.Marshal(ints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotInts Set[int]
+	if err := json.Unmarshal(data, &gotInts); err != nil {
+		t.Fatal(err)
+	}
+	if !gotInts.Equal(ints) {
+		t.Errorf("ints came back as %v", gotInts)
+	}
+
+	// Inside another struct too, since that's how it'll usually get used.
+	type board struct {
+		Walls Set[Coord] `json:"walls"`
+	}
+	in := board{Walls: New(Coord{1, 2}, Coord{-3, 4})}
+	data, err = json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"walls":[{"x":-3,"y":4},{"x":1,"y":2}]}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+	var out board
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Walls.Equal(in.Walls) {
+		t.Errorf("coords came back as %v", out.Walls)
+	}
+}
+
+func TestJSONDuplicatesAndNull(t *testing.T) {
+	var s Set[int]
+	if err := json.Unmarshal([]byte(`[3,1,3,3]`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(New(1, 3)) {
+		t.Errorf("got %v, want {1 3}", s)
+	}
+
+	// null leaves the set empty but still usable.
+	s = New(1, 2)
+	if err := json.Unmarshal([]byte(`null`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || s.Len() != 0 {
+		t.Errorf("after null got %v, want an empty set", s)
+	}
+	s.Add(5)
+
+	if err := json.Unmarshal([]byte(`{"1":{}}`), &s); err == nil {
+		t.Error("unmarshalling an object worked, want an error")
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	in := New(Coord{2, 0}, Coord{10, 0})
+	text, err := in.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"x":2,"y":0},{"x":10,"y":0}]`; string(text) != want {
+		t.Errorf("MarshalText() = %s, want %s", text, want)
+	}
+
+	var out Set[Coord]
+	if err := out.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Equal(in) {
+		t.Errorf("got %v back, want %v", out, in)
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	type saved struct {
+		Numbers Set[int]
+		Walls   Set[Coord]
+	}
+	in := saved{
+		Numbers: New(5, 10, 15),
+		Walls:   New(Coord{0, 0}, Coord{3, -3}),
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatal(err)
+	}
+	var out saved
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Numbers.Equal(in.Numbers) || !out.Walls.Equal(in.Walls) {
+		t.Errorf("got %+v back, want %+v", out, in)
+	}
+}
@@ -0,0 +1,101 @@
+package finitesets
+
+import (
+	"container/list"
+	"iter"
+
+	"mergesort"
+)
+
+// Sets are un-ordered, which is usually fine, but ranging over a map gives you a
+// different order every single time. That's a pain if you're printing a report or
+// comparing output against a file. An OrderedSet remembers the order things were
+// added in.
+//
+// The trick is to keep two things: a doubly linked list (container/list) holding
+// the items in order, and a map from each item to its spot in the list. The map
+// gives us the quick "is this in here?" check, and since it points right at the
+// list element, removing something from the middle of the list doesn't require
+// searching for it. So adding, removing and checking are all still O(1).
+
+// OrderedSet is a set that remembers the order items were added in. Like Set, the
+// zero value isn't ready to use. Add on an OrderedSet{} panics, since the map and
+// list inside it were never made, so always make one with NewOrdered.
+type OrderedSet[T comparable] struct {
+	items map[T]*list.Element
+	order *list.List
+}
+
+// NewOrdered makes an OrderedSet, and adds any items you pass in, in that order.
+func NewOrdered[T comparable](items ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{
+		items: make(map[T]*list.Element, len(items)),
+		order: list.New(),
+	}
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// Add puts item at the end of the set. If it's already in there, it stays where
+// it was.
+func (s *OrderedSet[T]) Add(item T) {
+	if _, ok := s.items[item]; ok {
+		return
+	}
+	s.items[item] = s.order.PushBack(item)
+}
+
+// Remove takes item out of the set.
+func (s *OrderedSet[T]) Remove(item T) {
+	if e, ok := s.items[item]; ok {
+		s.order.Remove(e)
+		delete(s.items, item)
+	}
+}
+
+// Contains reports whether item is in the set.
+func (s *OrderedSet[T]) Contains(item T) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+// Len is how many items are in the set.
+func (s *OrderedSet[T]) Len() int {
+	return len(s.items)
+}
+
+// Clear empties the set out.
+func (s *OrderedSet[T]) Clear() {
+	clear(s.items)
+	s.order.Init()
+}
+
+// All ranges over the set in the order items were added. Unlike Set, this is the
+// same every time.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := s.order.Front(); e != nil; e = e.Next() {
+			if !yield(e.Value.(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Items returns the items in the order they were added, as a slice.
+func (s *OrderedSet[T]) Items() []T {
+	retval := make([]T, 0, s.Len())
+	for item := range s.All() {
+		retval = append(retval, item)
+	}
+	return retval
+}
+
+// Sorted returns the items sorted by cmp instead of by when they were added, using
+// the mergesort package. Since that sort is stable, anything cmp says is equal stays
+// in insertion order. The set itself isn't changed.
+func (s *OrderedSet[T]) Sorted(cmp func(a, b T) int) []T {
+	return mergesort.MergeSortFunc(s.Items(), cmp)
+}
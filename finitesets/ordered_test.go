@@ -0,0 +1,87 @@
+package finitesets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := NewOrdered("c", "a", "b", "a")
+	if got := s.Items(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Fatalf("Items() = %v, want [c a b]", got)
+	}
+
+	// Adding something that's already there doesn't move it.
+	s.Add("c")
+	if got := s.Items(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("after adding c again, Items() = %v, want [c a b]", got)
+	}
+
+	// But taking it out and putting it back does, it's new again.
+	s.Remove("c")
+	if s.Contains("c") || s.Len() != 2 {
+		t.Errorf("after removing c: Contains = %v, Len = %d", s.Contains("c"), s.Len())
+	}
+	s.Add("c")
+	if got := s.Items(); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("after removing and re-adding c, Items() = %v, want [a b c]", got)
+	}
+
+	// Removing something that isn't there does nothing.
+	s.Remove("z")
+	if s.Len() != 3 {
+		t.Errorf("Len() = %d after removing something missing, want 3", s.Len())
+	}
+
+	// Stopping early works.
+	for item := range s.All() {
+		if item != "a" {
+			t.Errorf("first item = %q, want a", item)
+		}
+		break
+	}
+}
+
+func TestOrderedSetClear(t *testing.T) {
+	s := NewOrdered(1, 2, 3)
+	s.Clear()
+	if s.Len() != 0 || s.Contains(1) || len(s.Items()) != 0 {
+		t.Fatalf("after Clear: Len = %d, Items = %v", s.Len(), s.Items())
+	}
+
+	// It should still work like new afterwards.
+	s.Add(3)
+	s.Add(1)
+	if got := s.Items(); !slices.Equal(got, []int{3, 1}) {
+		t.Errorf("Items() after Clear and Add = %v, want [3 1]", got)
+	}
+}
+
+func TestOrderedSetSorted(t *testing.T) {
+	// Sorting by length makes a few of these equal, and those have to stay in
+	// the order they were added.
+	s := NewOrdered("ccc", "b", "aa", "a", "bb", "c")
+	byLen := func(a, b string) int { return len(a) - len(b) }
+
+	want := []string{"b", "a", "c", "aa", "bb", "ccc"}
+	if got := s.Sorted(byLen); !slices.Equal(got, want) {
+		t.Errorf("Sorted() = %v, want %v", got, want)
+	}
+	if got := s.Items(); !slices.Equal(got, []string{"ccc", "b", "aa", "a", "bb", "c"}) {
+		t.Errorf("Sorted() changed the set: %v", got)
+	}
+	if got := s.Sorted(strings.Compare); !slices.Equal(got, []string{"a", "aa", "b", "bb", "c", "ccc"}) {
+		t.Errorf("Sorted(strings.Compare) = %v", got)
+	}
+}
+
+func TestOrderedSetZeroValue(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Add on the zero value didn't panic, update the doc on OrderedSet")
+		}
+	}()
+	var s OrderedSet[int]
+	s.Add(1)
+}
@@ -0,0 +1,294 @@
+package finitesets
+
+import (
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// If you want to hand a set off to another goroutine as a snapshot, the safe thing
+// to do with a regular Set is Clone() it, which copies the whole map every time.
+// That gets expensive. A persistent set takes a different approach: it never
+// changes at all. With(x) and Without(x) give you back a _new_ set, and the old one
+// is exactly as it was. Since nothing is ever modified, any number of goroutines can
+// read any version without locking anything.
+//
+// That would be just as slow as cloning if every new version was a full copy, but
+// it isn't. The items live in a tree (a "hash array mapped trie", or HAMT), and a
+// new version only copies the handful of nodes on the path from the root down to
+// where the change happened. Everything else is shared with the old version.
+//
+// How the tree works: hash the item, then use the hash 5 bits at a time to pick
+// which of 32 branches to follow at each level. Instead of every node having room
+// for all 32 branches, it has a 32 bit "bitmap" saying which branches actually
+// exist, plus a slice with just those. To find branch i in the slice, count how
+// many bits are set in the bitmap below bit i (bits.OnesCount32 again).
+
+const (
+	hamtBits = 5               // how many bits of the hash each level uses
+	hamtMask = 1<<hamtBits - 1 // pulls those bits out
+)
+
+// PersistentSet is an immutable set. The zero value is an empty set that's ready
+// to use.
+type PersistentSet[T comparable] struct {
+	root *hamtNode[T]
+	size int
+	seed maphash.Seed
+}
+
+// hamtNode is one level of the tree.
+type hamtNode[T comparable] struct {
+	bitmap   uint32
+	children []hamtEntry[T]
+}
+
+// hamtEntry is one branch of a node. It's either another node, or a leaf holding
+// the items whose hash ends up here. There's normally only one item in a leaf, but
+// two different items can have the exact same hash, so it's a slice just in case.
+type hamtEntry[T comparable] struct {
+	node  *hamtNode[T]
+	hash  uint64
+	items []T
+}
+
+// NewPersistent makes a PersistentSet holding items.
+func NewPersistent[T comparable](items ...T) PersistentSet[T] {
+	var s PersistentSet[T]
+	for _, item := range items {
+		s = s.With(item)
+	}
+	return s
+}
+
+// Len is how many items are in the set.
+func (s PersistentSet[T]) Len() int {
+	return s.size
+}
+
+// Contains reports whether item is in the set.
+func (s PersistentSet[T]) Contains(item T) bool {
+	if s.root == nil {
+		return false
+	}
+	hash := maphash.Comparable(s.seed, item)
+
+	n := s.root
+	for shift := 0; ; shift += hamtBits {
+		bit := uint32(1) << ((hash >> shift) & hamtMask)
+		if n.bitmap&bit == 0 {
+			return false
+		}
+		e := n.children[n.index(bit)]
+		if e.node == nil {
+			return e.hash == hash && slices.Contains(e.items, item)
+		}
+		n = e.node
+	}
+}
+
+// With returns a new set that has everything in s, plus item. s doesn't change.
+func (s PersistentSet[T]) With(item T) PersistentSet[T] {
+	// Every version of a set has to hash things the same way, so the seed gets
+	// picked once, the first time something is added, and passed along.
+	if s.seed == (maphash.Seed{}) {
+		s.seed = maphash.MakeSeed()
+	}
+	hash := maphash.Comparable(s.seed, item)
+
+	root := s.root
+	if root == nil {
+		root = &hamtNode[T]{}
+	}
+
+	newRoot, added := root.with(hash, 0, item)
+	if !added {
+		return s
+	}
+	return PersistentSet[T]{root: newRoot, size: s.size + 1, seed: s.seed}
+}
+
+// Without returns a new set that has everything in s except item. s doesn't change.
+func (s PersistentSet[T]) Without(item T) PersistentSet[T] {
+	if s.root == nil {
+		return s
+	}
+	hash := maphash.Comparable(s.seed, item)
+
+	newRoot, removed := s.root.without(hash, 0, item)
+	if !removed {
+		return s
+	}
+	return PersistentSet[T]{root: newRoot, size: s.size - 1, seed: s.seed}
+}
+
+// All ranges over every item in the set, in no particular order.
+func (s PersistentSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if s.root != nil {
+			s.root.all(yield)
+		}
+	}
+}
+
+// ToSet copies the items into a regular Set.
+func (s PersistentSet[T]) ToSet() Set[T] {
+	retval := make(Set[T], s.size)
+	for item := range s.All() {
+		retval.Add(item)
+	}
+	return retval
+}
+
+// index finds where the branch for bit is in n.children.
+func (n *hamtNode[T]) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// with returns a copy of n with item added, and whether it was actually added.
+// If it was already there, n itself comes back untouched.
+func (n *hamtNode[T]) with(hash uint64, shift int, item T) (*hamtNode[T], bool) {
+	bit := uint32(1) << ((hash >> shift) & hamtMask)
+	i := n.index(bit)
+
+	// Nothing on this branch yet, so the item can go right here.
+	if n.bitmap&bit == 0 {
+		leaf := hamtEntry[T]{hash: hash, items: []T{item}}
+		return &hamtNode[T]{
+			bitmap:   n.bitmap | bit,
+			children: slices.Insert(slices.Clone(n.children), i, leaf),
+		}, true
+	}
+
+	e := n.children[i]
+	var replacement hamtEntry[T]
+
+	switch {
+	case e.node != nil:
+		// Another level down, so keep going.
+		child, added := e.node.with(hash, shift+hamtBits, item)
+		if !added {
+			return n, false
+		}
+		replacement = hamtEntry[T]{node: child}
+
+	case e.hash == hash:
+		// A leaf with the exact same hash. Either it's the same item, or it's
+		// one of those rare collisions and they have to share the leaf.
+		if slices.Contains(e.items, item) {
+			return n, false
+		}
+		replacement = hamtEntry[T]{hash: hash, items: append(slices.Clone(e.items), item)}
+
+	default:
+		// A leaf for some other hash is in the way. Push both of them down into
+		// a new node where they'll (eventually) land on different branches.
+		leaf := hamtEntry[T]{hash: hash, items: []T{item}}
+		replacement = hamtEntry[T]{node: splitLeaves(e, leaf, shift+hamtBits)}
+	}
+
+	// This is the path copying part. Only this node gets copied, and it still
+	// points at all the same children as before except the one that changed.
+	children := slices.Clone(n.children)
+	children[i] = replacement
+	return &hamtNode[T]{bitmap: n.bitmap, children: children}, true
+}
+
+// splitLeaves builds a node holding two leaves with different hashes, adding more
+// levels for as long as their hashes keep picking the same branch.
+func splitLeaves[T comparable](a, b hamtEntry[T], shift int) *hamtNode[T] {
+	ia := (a.hash >> shift) & hamtMask
+	ib := (b.hash >> shift) & hamtMask
+
+	if ia == ib {
+		return &hamtNode[T]{
+			bitmap:   1 << ia,
+			children: []hamtEntry[T]{{node: splitLeaves(a, b, shift+hamtBits)}},
+		}
+	}
+
+	// The children have to be in the same order as their bits.
+	if ia > ib {
+		a, b = b, a
+		ia, ib = ib, ia
+	}
+	return &hamtNode[T]{
+		bitmap:   1<<ia | 1<<ib,
+		children: []hamtEntry[T]{a, b},
+	}
+}
+
+// without returns a copy of n with item removed, and whether it was actually
+// there. If a node ends up empty, it comes back as nil so the parent can drop it.
+func (n *hamtNode[T]) without(hash uint64, shift int, item T) (*hamtNode[T], bool) {
+	bit := uint32(1) << ((hash >> shift) & hamtMask)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	i := n.index(bit)
+	e := n.children[i]
+
+	var replacement hamtEntry[T]
+	keep := true
+
+	if e.node != nil {
+		child, removed := e.node.without(hash, shift+hamtBits, item)
+		if !removed {
+			return n, false
+		}
+		switch {
+		case child == nil:
+			keep = false
+		case len(child.children) == 1 && child.children[0].node == nil:
+			// If all that's left down there is one leaf, pull it back up
+			// here so the tree doesn't keep a chain of nearly empty nodes.
+			replacement = child.children[0]
+		default:
+			replacement = hamtEntry[T]{node: child}
+		}
+	} else {
+		j := slices.Index(e.items, item)
+		if e.hash != hash || j < 0 {
+			return n, false
+		}
+		if len(e.items) == 1 {
+			keep = false
+		} else {
+			replacement = hamtEntry[T]{hash: hash, items: slices.Delete(slices.Clone(e.items), j, j+1)}
+		}
+	}
+
+	if keep {
+		children := slices.Clone(n.children)
+		children[i] = replacement
+		return &hamtNode[T]{bitmap: n.bitmap, children: children}, true
+	}
+
+	// The branch is gone entirely, so take it out of the bitmap too.
+	if len(n.children) == 1 {
+		return nil, true
+	}
+	return &hamtNode[T]{
+		bitmap:   n.bitmap &^ bit,
+		children: slices.Delete(slices.Clone(n.children), i, i+1),
+	}, true
+}
+
+// all walks every leaf under n. It returns false once yield says to stop.
+func (n *hamtNode[T]) all(yield func(T) bool) bool {
+	for _, e := range n.children {
+		if e.node != nil {
+			if !e.node.all(yield) {
+				return false
+			}
+			continue
+		}
+		for _, item := range e.items {
+			if !yield(item) {
+				return false
+			}
+		}
+	}
+	return true
+}
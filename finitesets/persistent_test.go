@@ -0,0 +1,228 @@
+package finitesets
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// The public API hashes with a random seed, so there's no way to make two items
+// collide through it on purpose. These tests build trees straight from the node
+// methods instead, where we get to pick the hashes.
+
+// lookup finds item under n the same way PersistentSet.Contains does.
+func lookup[T comparable](n *hamtNode[T], hash uint64, item T) bool {
+	for shift := 0; n != nil; shift += hamtBits {
+		bit := uint32(1) << ((hash >> shift) & hamtMask)
+		if n.bitmap&bit == 0 {
+			return false
+		}
+		e := n.children[n.index(bit)]
+		if e.node == nil {
+			return e.hash == hash && slices.Contains(e.items, item)
+		}
+		n = e.node
+	}
+	return false
+}
+
+// depth is how many levels of nodes the tree has.
+func depth[T comparable](n *hamtNode[T]) int {
+	if n == nil {
+		return 0
+	}
+	deepest := 0
+	for _, e := range n.children {
+		if e.node != nil {
+			deepest = max(deepest, depth(e.node))
+		}
+	}
+	return deepest + 1
+}
+
+// items collects everything under n.
+func items[T comparable](n *hamtNode[T]) []T {
+	var retval []T
+	if n != nil {
+		n.all(func(item T) bool {
+			retval = append(retval, item)
+			return true
+		})
+	}
+	return retval
+}
+
+func TestHAMTCollisions(t *testing.T) {
+	// Three different items with the exact same hash have to share one leaf.
+	const hash = 0xfeedface
+	root := &hamtNode[string]{}
+	for _, s := range []string{"a", "b", "c"} {
+		var added bool
+		root, added = root.with(hash, 0, s)
+		if !added {
+			t.Fatalf("with(%q) said it was already there", s)
+		}
+	}
+	if _, added := root.with(hash, 0, "b"); added {
+		t.Error("adding b a second time said it was new")
+	}
+	if len(root.children) != 1 || len(root.children[0].items) != 3 {
+		t.Fatalf("expected one leaf with three items, got %+v", root.children)
+	}
+
+	// Something with the same hash that isn't in there.
+	if lookup(root, hash, "d") {
+		t.Error("found d, which was never added")
+	}
+	if _, removed := root.without(hash, 0, "d"); removed {
+		t.Error("removing d said it was there")
+	}
+
+	// Take them back out one at a time, checking the leftovers each time.
+	withoutB, removed := root.without(hash, 0, "b")
+	if !removed || !lookup(withoutB, hash, "a") || lookup(withoutB, hash, "b") || !lookup(withoutB, hash, "c") {
+		t.Errorf("after removing b: %v", items(withoutB))
+	}
+	withoutA, _ := withoutB.without(hash, 0, "a")
+	if got := items(withoutA); !slices.Equal(got, []string{"c"}) {
+		t.Errorf("after removing a and b: %v", got)
+	}
+	empty, removed := withoutA.without(hash, 0, "c")
+	if !removed || empty != nil {
+		t.Errorf("removing the last item should leave nil, got %+v", empty)
+	}
+
+	// None of that should have changed the original.
+	if got := items(root); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("original tree changed to %v", got)
+	}
+}
+
+func TestHAMTPullUp(t *testing.T) {
+	// These two hashes agree on the bottom 35 bits, so they can't be told apart
+	// until the eighth level. Adding both builds a long chain of one-branch
+	// nodes down to where they finally split.
+	const (
+		h1 = 0x0_0000_0001
+		h2 = 0x8_0000_0001
+		h3 = 0x2 // and this one is on a different branch right at the top
+	)
+	root := &hamtNode[int]{}
+	root, _ = root.with(h1, 0, 1)
+	root, _ = root.with(h2, 0, 2)
+	root, _ = root.with(h3, 0, 3)
+
+	if d := depth(root); d != 8 {
+		t.Fatalf("depth = %d, want 8", d)
+	}
+	for _, c := range []struct {
+		hash uint64
+		item int
+	}{{h1, 1}, {h2, 2}, {h3, 3}} {
+		if !lookup(root, c.hash, c.item) {
+			t.Errorf("couldn't find %d", c.item)
+		}
+	}
+
+	// Taking one of the pair out leaves a single leaf at the bottom of the chain.
+	// It should get pulled all the way back up to the root, since there's nothing
+	// left down there to tell it apart from.
+	pulled, removed := root.without(h2, 0, 2)
+	if !removed {
+		t.Fatal("without(2) said it wasn't there")
+	}
+	if d := depth(pulled); d != 1 {
+		t.Errorf("depth after removing = %d, want 1", d)
+	}
+	if !lookup(pulled, h1, 1) || !lookup(pulled, h3, 3) || lookup(pulled, h2, 2) {
+		t.Errorf("wrong items after pull-up: %v", items(pulled))
+	}
+
+	// And the old version still has its whole chain.
+	if d := depth(root); d != 8 || !lookup(root, h2, 2) {
+		t.Errorf("original tree changed: depth %d, items %v", depth(root), items(root))
+	}
+}
+
+// Every version ever made should still hold exactly what it did when it was made,
+// no matter what happened to the versions after it.
+func TestPersistentSetOldVersions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	var versions []PersistentSet[int]
+	var want []Set[int]
+
+	s := NewPersistent[int]()
+	current := New[int]()
+	for range 3000 {
+		x := r.Intn(500)
+		if r.Intn(3) == 0 {
+			s = s.Without(x)
+			current.Remove(x)
+		} else {
+			s = s.With(x)
+			current.Add(x)
+		}
+		versions = append(versions, s)
+		want = append(want, current.Clone())
+	}
+
+	for i, v := range versions {
+		if v.Len() != want[i].Len() {
+			t.Fatalf("version %d: Len() = %d, want %d", i, v.Len(), want[i].Len())
+		}
+		if !v.ToSet().Equal(want[i]) {
+			t.Fatalf("version %d changed", i)
+		}
+		for x := range 500 {
+			if v.Contains(x) != want[i].Contains(x) {
+				t.Fatalf("version %d: Contains(%d) = %v", i, x, v.Contains(x))
+			}
+		}
+	}
+}
+
+func TestPersistentSetZeroValue(t *testing.T) {
+	var s PersistentSet[string]
+	if s.Len() != 0 || s.Contains("x") {
+		t.Error("zero value isn't empty")
+	}
+	if s.Without("x").Len() != 0 {
+		t.Error("Without on an empty set did something")
+	}
+	if !s.With("x").Contains("x") {
+		t.Error("With on the zero value didn't add")
+	}
+	if s.With("x").Without("x").Len() != 0 {
+		t.Error("adding and removing didn't get back to empty")
+	}
+}
+
+// The whole point: adding one thing to a big set and keeping the old version too.
+// With a regular Set that means cloning the map first.
+const benchPersistent = 100_000
+
+func BenchmarkPersistentSnapshot(b *testing.B) {
+	s := NewPersistent[int]()
+	for i := range benchPersistent {
+		s = s.With(i)
+	}
+	i := benchPersistent
+	for b.Loop() {
+		_ = s.With(i)
+		i++
+	}
+}
+
+func BenchmarkCloneSnapshot(b *testing.B) {
+	s := New[int]()
+	for i := range benchPersistent {
+		s.Add(i)
+	}
+	i := benchPersistent
+	for b.Loop() {
+		c := s.Clone()
+		c.Add(i)
+		i++
+	}
+}
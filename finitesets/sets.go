@@ -1,63 +1,90 @@
 package finitesets
 
-import "fmt"
+import (
+	"iter"
+	"maps"
+)
 
 /*
  * Finite sets aren't a built-in like they are in Python. Sets are an incredibly useful
  * data type that allows you to add things to a list, _but not if it already exists in
  * the list_. They are un-ordered unlike an array, and are functionally easy to use once
  * you have it built. Here's how to make them.
+ *
+ * The trick is a map whose values are empty structs, which take up zero bytes. The
+ * Set type below is exactly that, a map[T]struct{}, just with some methods hung off
+ * of it so you don't have to remember the syntax every time. Check out
+ * cmd/finitesets for the walkthrough of how it works under the hood.
  */
 
 // This object is not relating to structs directly, I am only doing this for reference later.
+// The field tags are so it marshals nicely as {"x":50,"y":100}, same as the Game struct
+// in the marshalling module.
 type Coord struct {
-	X, Y int
-}
-
-func main() {
-	// The easiest (and smallest in memory) way to create a set is to use a map object
-	// and set the values equal to an empty struct. You can set it to a boolean object
-	// if you want, but each boolean object takes up one byte. Why use one byte when
-	// you could use no bytes?
-
-	// This sets up a composite literal struct object. Essentially the first set of
-	// brackets state that it is an actionable struct object, and the second set of
-	// brackets state that the struct object consists of literally nothing. Note that
-	// we're declaring this variable so no need to infer a datatype.
-	var exists = struct{}{}
-
-	// Now we'll create a set object by making a map object of whatever type of data
-	// we want to add to the set (in this case I'll choose an integer, but you can
-	// literally use any valid Go object, even another struct object).
-	mySet := make(map[int]struct{})
-
-	// Now we can add to the set by setting any value equal to the `exists` variable
-	// we created above.
-	mySet[50] = exists
-	mySet[100] = exists
-	mySet[9001] = exists
-	mySet[100] = exists
-
-	// The above won't error out even though we already created an object with the value
-	// of 100. We can confirm it exists with a quick 'if' statement.
-	if _, ok := mySet[100]; ok {
-		fmt.Println("100 exists in the set!")
-	}
+	X int `json:"x"`
+	Y int `json:"y"`
+}
 
-	// As I said before, we're not limited to common datatypes for items in the set. We can
-	// even use structs!
-	myCoordSet := make(map[Coord]struct{})
+// This sets up a composite literal struct object. Essentially the first set of
+// brackets state that it is an actionable struct object, and the second set of
+// brackets state that the struct object consists of literally nothing.
+var exists = struct{}{}
 
-	myCoordSet[Coord{50, 100}] = exists
-	myCoordSet[Coord{45, 20}] = exists
-	myCoordSet[Coord{50, 100}] = exists
+// Set is a set of any comparable type, which is anything you could use as a map key
+// (ints, strings, even structs like Coord). Since it's just a map underneath, you
+// need to make one with New before adding to it, same as you would with make().
+type Set[T comparable] map[T]struct{}
 
-	if _, ok := myCoordSet[Coord{50, 100}]; ok {
-		fmt.Println("The coordinates of X: 50, Y: 100 exist!")
+// New makes an empty set, and adds any items you pass in.
+func New[T comparable](items ...T) Set[T] {
+	s := make(Set[T], len(items))
+	for _, item := range items {
+		s.Add(item)
 	}
+	return s
+}
+
+// Add puts item in the set. Adding something that's already there does nothing.
+func (s Set[T]) Add(item T) {
+	s[item] = exists
+}
+
+// Remove takes item out of the set, if it's even there.
+func (s Set[T]) Remove(item T) {
+	delete(s, item)
+}
 
-	// You can even loop through the values using a for loop.
-	for k := range mySet {
-		fmt.Printf("Value: %d\n", k)
+// Contains reports whether item is in the set.
+func (s Set[T]) Contains(item T) bool {
+	_, ok := s[item]
+	return ok
+}
+
+// Len is how many items are in the set.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clear empties the set out.
+func (s Set[T]) Clear() {
+	clear(s)
+}
+
+// Clone makes a copy of the set. Changing one won't change the other.
+func (s Set[T]) Clone() Set[T] {
+	// maps.Clone keeps a nil map nil, but we always want something we can add to.
+	if s == nil {
+		return New[T]()
 	}
+	return maps.Clone(s)
+}
+
+// All lets you range over every item in the set. Just like ranging over a map,
+// the order is random every time!
+//
+//	for item := range mySet.All() {
+//		fmt.Println(item)
+//	}
+func (s Set[T]) All() iter.Seq[T] {
+	return maps.Keys(s)
 }
@@ -0,0 +1,192 @@
+package finitesets
+
+import (
+	"hash/maphash"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// Maps in Go are NOT safe to use from more than one goroutine at a time if anybody
+// is writing to them, and since Set is just a map, neither is Set. If a bunch of
+// workers all try to add to the same Set at once, Go will straight up crash with
+// "concurrent map writes". The fix is the same one the Guestbook in the channels
+// module uses: a mutex.
+//
+// This one uses a sync.RWMutex instead of a plain sync.Mutex though. A RWMutex lets
+// any number of readers in at the same time (RLock), and only makes everybody wait
+// when someone needs to write (Lock). Sets tend to get checked way more often than
+// they get changed, so that helps a lot.
+
+// SyncSet is a Set that's safe to use from multiple goroutines at once.
+type SyncSet[T comparable] struct {
+	mut sync.RWMutex
+	set Set[T]
+}
+
+// NewSync makes a SyncSet, and adds any items you pass in. Always pass a SyncSet
+// around as a pointer, since copying it would copy the mutex too.
+func NewSync[T comparable](items ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: New(items...)}
+}
+
+// Add puts item in the set.
+func (s *SyncSet[T]) Add(item T) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.set.Add(item)
+}
+
+// AddNew puts item in the set and reports whether it's new, all in one step. This
+// is what you want for deduplicating from a bunch of workers. If you called
+// Contains and then Add instead, two workers could both see false from Contains
+// before either of them gets to Add, and they'd both think they were first.
+func (s *SyncSet[T]) AddNew(item T) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.set.Contains(item) {
+		return false
+	}
+	s.set.Add(item)
+	return true
+}
+
+// Remove takes item out of the set.
+func (s *SyncSet[T]) Remove(item T) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.set.Remove(item)
+}
+
+// Contains reports whether item is in the set. Only needs a read lock!
+func (s *SyncSet[T]) Contains(item T) bool {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	return s.set.Contains(item)
+}
+
+// Len is how many items are in the set.
+func (s *SyncSet[T]) Len() int {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	return s.set.Len()
+}
+
+// Clear empties the set out.
+func (s *SyncSet[T]) Clear() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.set.Clear()
+}
+
+// Snapshot returns a plain Set copy of what's in the set right now. Since it's a
+// copy, you can do whatever you want with it without any locking.
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	return s.set.Clone()
+}
+
+// All ranges over a snapshot of the set. We don't hold the lock while you're
+// looping, otherwise anything in your loop that tries to Add would deadlock.
+func (s *SyncSet[T]) All() iter.Seq[T] {
+	return s.Snapshot().All()
+}
+
+// A single SyncSet is fine until you have hundreds of goroutines all hammering on
+// it at once. Then they spend all of their time waiting on each other for that one
+// lock. The fix is to split the set into a bunch of smaller SyncSets ("shards"),
+// each with its own lock, and always put an item in the same shard by hashing it.
+// Two workers only have to wait on each other if their items land in the same
+// shard.
+
+// ShardedSet is a SyncSet split into shards to cut down on lock contention.
+type ShardedSet[T comparable] struct {
+	seed   maphash.Seed
+	shards []*SyncSet[T]
+}
+
+// NewSharded makes a ShardedSet with the given number of shards. Zero or less
+// picks a number based on how many CPUs there are.
+func NewSharded[T comparable](shards int) *ShardedSet[T] {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+
+	s := &ShardedSet[T]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*SyncSet[T], shards),
+	}
+	for i := range s.shards {
+		s.shards[i] = NewSync[T]()
+	}
+	return s
+}
+
+// shard picks which shard item belongs in. maphash.Comparable hashes anything
+// that can be a map key, which is exactly what we need.
+func (s *ShardedSet[T]) shard(item T) *SyncSet[T] {
+	h := maphash.Comparable(s.seed, item)
+	return s.shards[h%uint64(len(s.shards))]
+}
+
+// Add puts item in the set.
+func (s *ShardedSet[T]) Add(item T) {
+	s.shard(item).Add(item)
+}
+
+// AddNew puts item in the set and reports whether it's new. See SyncSet.AddNew.
+func (s *ShardedSet[T]) AddNew(item T) bool {
+	return s.shard(item).AddNew(item)
+}
+
+// Remove takes item out of the set.
+func (s *ShardedSet[T]) Remove(item T) {
+	s.shard(item).Remove(item)
+}
+
+// Contains reports whether item is in the set.
+func (s *ShardedSet[T]) Contains(item T) bool {
+	return s.shard(item).Contains(item)
+}
+
+// Len adds up the length of every shard. If other goroutines are changing the set
+// while this runs, it's only a rough count.
+func (s *ShardedSet[T]) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+// Clear empties every shard.
+func (s *ShardedSet[T]) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+// Snapshot copies every shard into a single plain Set. Like Len, each shard is
+// copied one at a time, so it isn't one consistent moment across the whole set.
+func (s *ShardedSet[T]) Snapshot() Set[T] {
+	retval := New[T]()
+	for _, shard := range s.shards {
+		for item := range shard.All() {
+			retval.Add(item)
+		}
+	}
+	return retval
+}
+
+// All ranges over a snapshot of the set.
+func (s *ShardedSet[T]) All() iter.Seq[T] {
+	return s.Snapshot().All()
+}
@@ -0,0 +1,125 @@
+package finitesets
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// These are only really worth anything with the race detector on:
+//
+//	go test -race ./finitesets
+
+// concurrentSet is what SyncSet and ShardedSet have in common, so the same tests
+// can run against both of them.
+type concurrentSet interface {
+	Add(int)
+	AddNew(int) bool
+	Remove(int)
+	Contains(int) bool
+	Len() int
+	Snapshot() Set[int]
+	All() iter.Seq[int]
+}
+
+var concurrentSets = []struct {
+	name string
+	make func() concurrentSet
+}{
+	{"SyncSet", func() concurrentSet { return NewSync[int]() }},
+	{"ShardedSet", func() concurrentSet { return NewSharded[int](0) }},
+	{"ShardedSet with 1 shard", func() concurrentSet { return NewSharded[int](1) }},
+}
+
+const (
+	goroutines = 300
+	perWorker  = 200
+)
+
+// Every goroutine adds its own range of numbers, while also reading and removing
+// things, so there are readers and writers going at the same time.
+func TestConcurrentSetHammer(t *testing.T) {
+	for _, cs := range concurrentSets {
+		t.Run(cs.name, func(t *testing.T) {
+			s := cs.make()
+
+			var wg sync.WaitGroup
+			for g := range goroutines {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					base := g * perWorker
+					for i := range perWorker {
+						s.Add(base + i)
+						if !s.Contains(base + i) {
+							t.Errorf("Contains(%d) = false right after Add", base+i)
+						}
+						// Take the odd ones back out again.
+						if i%2 == 1 {
+							s.Remove(base + i)
+						}
+						// And read the whole thing now and then.
+						if i%100 == 0 {
+							s.Len()
+							for range s.All() {
+							}
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got, want := s.Len(), goroutines*perWorker/2; got != want {
+				t.Errorf("Len() = %d, want %d", got, want)
+			}
+			snap := s.Snapshot()
+			for i := range goroutines * perWorker {
+				if snap.Contains(i) != (i%2 == 0) {
+					t.Errorf("Contains(%d) = %v afterwards", i, snap.Contains(i))
+				}
+			}
+		})
+	}
+}
+
+// This is the whole point of AddNew: every goroutine tries to add the same items,
+// and for each item exactly one of them gets told it was first.
+func TestConcurrentSetAddNew(t *testing.T) {
+	const items = 1000
+
+	for _, cs := range concurrentSets {
+		t.Run(cs.name, func(t *testing.T) {
+			s := cs.make()
+			var winners [items]atomic.Int32
+
+			// Everyone waits at the starting line so they really do pile on
+			// at the same time.
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for range goroutines {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					for i := range items {
+						if s.AddNew(i) {
+							winners[i].Add(1)
+						}
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			for i := range items {
+				if n := winners[i].Load(); n != 1 {
+					t.Errorf("AddNew(%d) returned true %d times, want exactly once", i, n)
+				}
+			}
+			if s.Len() != items {
+				t.Errorf("Len() = %d, want %d", s.Len(), items)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package mergesort
+
+import "cmp"
+
+// Sorting by one field is easy enough, but sorting by a field, THEN by another one
+// to break ties, THEN by another one after that turns into a pile of nested if
+// statements pretty fast. This lets you build the comparator up piece by piece
+// instead, like so:
+//
+//	byGenreYearName := mergesort.By(func(g Game) string { return g.Genre }).
+//		Then(mergesort.ByDesc(func(g Game) int { return g.YearCreated })).
+//		Then(mergesort.By(func(g Game) string { return g.Name }))
+//
+//	sorted := mergesort.MergeSortFunc(games, byGenreYearName)
+//
+// You might be wondering why it isn't just By(...).ThenBy(...). Go doesn't let
+// methods have their own type parameters, so a method can't take a key function
+// that returns some other type than the one By was built with. Then takes a whole
+// Comparator instead, which gets around it.
+
+// Comparator is a comparison function that follows the same rules as cmp.Compare.
+// It's just a func, so you can hand it straight to MergeSortFunc or any of the
+// other *Func sorts.
+type Comparator[T any] func(a, b T) int
+
+// By builds a Comparator that orders items by whatever key returns, smallest first.
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// ByDesc is By, but largest first.
+func ByDesc[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return By(key).Reverse()
+}
+
+// Then returns a Comparator that uses c first, and only falls back to next when c
+// says the two items are equal.
+func (c Comparator[T]) Then(next Comparator[T]) Comparator[T] {
+	return func(a, b T) int {
+		if r := c(a, b); r != 0 {
+			return r
+		}
+		return next(a, b)
+	}
+}
+
+// Reverse flips the order c sorts in.
+func (c Comparator[T]) Reverse() Comparator[T] {
+	return func(a, b T) int {
+		return c(b, a)
+	}
+}
@@ -0,0 +1,83 @@
+package mergesort
+
+import (
+	"slices"
+	"testing"
+)
+
+// Game is the same shape as the one in the marshalling module.
+type Game struct {
+	Name        string `json:"name"`
+	YearCreated int    `json:"year"`
+	Genre       string `json:"genre"`
+}
+
+var games = []Game{
+	{"Metroid", 1986, "Action"},
+	{"Contra", 1987, "Action"},
+	{"Tetris", 1984, "Puzzle"},
+	{"Castlevania", 1986, "Action"},
+	{"Dr. Mario", 1990, "Puzzle"},
+	{"Zelda", 1986, "Adventure"},
+	{"Columns", 1990, "Puzzle"},
+	{"Mega Man", 1987, "Action"},
+}
+
+var (
+	byGenre  = By(func(g Game) string { return g.Genre })
+	byNewest = ByDesc(func(g Game) int { return g.YearCreated })
+	byName   = By(func(g Game) string { return g.Name })
+	byOldest = By(func(g Game) int { return g.YearCreated })
+)
+
+func TestByThen(t *testing.T) {
+	got := MergeSortFunc(slices.Clone(games), byGenre.Then(byNewest).Then(byName))
+	want := []Game{
+		{"Contra", 1987, "Action"},
+		{"Mega Man", 1987, "Action"},
+		{"Castlevania", 1986, "Action"},
+		{"Metroid", 1986, "Action"},
+		{"Zelda", 1986, "Adventure"},
+		{"Columns", 1990, "Puzzle"},
+		{"Dr. Mario", 1990, "Puzzle"},
+		{"Tetris", 1984, "Puzzle"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("genre, newest, name:\n got %v\nwant %v", got, want)
+	}
+}
+
+// Leave the name off, and games with the same genre and year are ties. Those have
+// to stay in the order they were in to begin with: Metroid before Castlevania,
+// Contra before Mega Man, Dr. Mario before Columns.
+func TestByThenStable(t *testing.T) {
+	got := MergeSortFunc(slices.Clone(games), byGenre.Then(byNewest))
+	want := []Game{
+		{"Contra", 1987, "Action"},
+		{"Mega Man", 1987, "Action"},
+		{"Metroid", 1986, "Action"},
+		{"Castlevania", 1986, "Action"},
+		{"Zelda", 1986, "Adventure"},
+		{"Dr. Mario", 1990, "Puzzle"},
+		{"Columns", 1990, "Puzzle"},
+		{"Tetris", 1984, "Puzzle"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("genre, newest:\n got %v\nwant %v", got, want)
+	}
+
+	// The other sorts should agree, since they're all stable.
+	inPlace := slices.Clone(games)
+	MergeSortInPlaceFunc(inPlace, nil, byGenre.Then(byNewest))
+	if !slices.Equal(inPlace, want) {
+		t.Errorf("MergeSortInPlaceFunc:\n got %v\nwant %v", inPlace, want)
+	}
+}
+
+func TestComparatorReverse(t *testing.T) {
+	got := MergeSortFunc(slices.Clone(games), byOldest.Reverse())
+	want := MergeSortFunc(slices.Clone(games), byNewest)
+	if !slices.Equal(got, want) {
+		t.Errorf("By(year).Reverse() and ByDesc(year) disagree:\n%v\n%v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package mergesort
+
+import (
+	"cmp"
+	"context"
+)
+
+// When a sort takes a few minutes, it'd be nice to be able to give up on it (say,
+// the user hit Ctrl-C) and to see how far along it is. The recursive version makes
+// that awkward since all the work is buried in the call stack, so this one sorts
+// "bottom up" instead. It makes passes over the slice, first merging every pair of
+// single items, then every pair of 2-item runs, then 4, and so on until the run is
+// the whole slice. In between merges is a perfect spot to check if we've been
+// cancelled and to report progress.
+
+// progressEvery is roughly how many items get merged between context checks and
+// progress reports. Checking after every tiny merge would spend more time checking
+// than sorting.
+const progressEvery = 1 << 16
+
+// MergeSortCtx is MergeSortCtxFunc for anything that satisfies cmp.Ordered.
+func MergeSortCtx[T cmp.Ordered](ctx context.Context, u []T, progress func(merged, total int)) error {
+	return MergeSortCtxFunc(ctx, u, cmp.Compare[T], progress)
+}
+
+// MergeSortCtxFunc sorts u in place using cmp, and gives up with ctx.Err() if ctx is
+// cancelled before it's done. If that happens, u still has all of its items but
+// only partially sorted.
+//
+// If progress isn't nil, it gets called every so often with how many items have
+// been merged so far and how many merges it'll take in total, which is enough to
+// draw a progress bar. The last call is always merged == total, even for a slice
+// too short to need any merging, where both are zero.
+func MergeSortCtxFunc[T any](ctx context.Context, u []T, cmp func(a, b T) int, progress func(merged, total int)) error {
+	return mergeSortCtx(ctx, u, nil, cmp, progress)
+}
+
+// mergeSortCtx is MergeSortCtxFunc with a scratch buffer passed in, so callers
+// sorting lots of slices (like ExternalSort) can reuse one. If buf is too small
+// a new one gets made.
+func mergeSortCtx[T any](ctx context.Context, u []T, buf []T, cmp func(a, b T) int, progress func(merged, total int)) error {
+	n := len(u)
+
+	// Every pass merges all n items once, and the number of passes is however
+	// many times we can double the run width before it covers the slice.
+	passes := 0
+	for width := 1; width < n; width *= 2 {
+		passes++
+	}
+	total := n * passes
+	merged := 0
+	sinceCheck := 0
+
+	// report checks the context and calls progress. Returns the error from the
+	// context if we should stop.
+	report := func() error {
+		sinceCheck = 0
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(merged, total)
+		}
+		return nil
+	}
+
+	// Bail out right away if we were cancelled before we even started.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// The left run can be up to (but not quite) the whole slice on the last
+	// pass, so the scratch buffer has to be able to hold that.
+	if len(buf) < n {
+		buf = make([]T, n)
+	}
+
+	for width := 1; width < n; width *= 2 {
+		for lo := 0; lo < n; lo += 2 * width {
+			mid := min(lo+width, n)
+			hi := min(lo+2*width, n)
+
+			// If there's no right side, this run just carries over to
+			// the next pass untouched.
+			if mid < hi {
+				mergeInPlace(u[lo:hi], mid-lo, buf, cmp)
+			}
+
+			merged += hi - lo
+			sinceCheck += hi - lo
+			if sinceCheck >= progressEvery {
+				if err := report(); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Always check in at the end of a pass, no matter how small it was.
+		if err := report(); err != nil {
+			return err
+		}
+	}
+
+	// With zero or one items there weren't any passes, but the caller still
+	// gets told we're done.
+	if passes == 0 {
+		return report()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package mergesort
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// Big enough that there are plenty of progress reports in the middle of a pass,
+// not just at the ends of them.
+const ctxSize = 5 * progressEvery
+
+func TestMergeSortCtx(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 1000, ctxSize} {
+		u := randomInts(n)
+		want := slices.Sorted(slices.Values(u))
+
+		var calls [][2]int
+		err := MergeSortCtx(context.Background(), u, func(merged, total int) {
+			calls = append(calls, [2]int{merged, total})
+		})
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		if !slices.Equal(u, want) {
+			t.Fatalf("n=%d: not sorted", n)
+		}
+
+		if len(calls) == 0 {
+			t.Fatalf("n=%d: progress never called", n)
+		}
+		for i, c := range calls {
+			if c[0] > c[1] {
+				t.Errorf("n=%d: call %d has merged %d > total %d", n, i, c[0], c[1])
+			}
+			if i > 0 && (c[0] < calls[i-1][0] || c[1] != calls[i-1][1]) {
+				t.Errorf("n=%d: call %d went from %v to %v", n, i, calls[i-1], c)
+			}
+		}
+		if last := calls[len(calls)-1]; last[0] != last[1] {
+			t.Errorf("n=%d: last call was %v, want merged == total", n, last)
+		}
+	}
+}
+
+func TestMergeSortCtxCancel(t *testing.T) {
+	u := randomInts(ctxSize)
+	orig := slices.Clone(u)
+
+	// Cancel as soon as the first report comes in, which is partway through the
+	// very first pass.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	err := MergeSortCtxFunc(ctx, u, cmp.Compare[int], func(merged, total int) {
+		calls++
+		cancel()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("progress called %d times after cancelling, want 1", calls)
+	}
+	if slices.IsSorted(u) {
+		t.Error("finished sorting even though it was cancelled")
+	}
+
+	// Nothing should have gone missing, it's just not all in order.
+	slices.Sort(u)
+	slices.Sort(orig)
+	if !slices.Equal(u, orig) {
+		t.Error("cancelling lost or duplicated items")
+	}
+}
+
+func TestMergeSortCtxAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	u := []int{3, 2, 1}
+	called := false
+	err := MergeSortCtx(ctx, u, func(merged, total int) { called = true })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if called || !slices.Equal(u, []int{3, 2, 1}) {
+		t.Error("did some work even though it was cancelled before starting")
+	}
+}
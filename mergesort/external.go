@@ -0,0 +1,334 @@
+package mergesort
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+)
+
+// Everything else in here assumes the whole slice fits in memory. But what if you
+// need to sort a file that's bigger than the RAM you have? This is where merge sort
+// really shines, because the merge step only ever needs to look at the _front_ of
+// each sorted list. So the plan is:
+//
+//  1. Read the file in chunks that DO fit in memory (kind of like ReadInChunks in
+//     the filesandstrings module, except we split on lines).
+//  2. Sort each chunk with MergeSortCtxFunc and write it out to a temp file.
+//     Each of these sorted temp files is called a "run".
+//  3. Open the runs and merge them together, always writing out whichever run has
+//     the smallest line at the front. MergeKSeq already does exactly that.
+//
+// If the whole file fits in the memory budget, we skip the temp files entirely.
+//
+// One catch with step 3: every open run is an open file, and the OS only lets you
+// have so many of those (ulimit -n is 1024 on plenty of systems). Sorting 100GB
+// with a 64MB budget makes about 1600 runs, so we can't just open them all. Instead
+// we merge at most maxFanIn runs at a time into bigger runs, and keep doing that
+// until there are few enough left to merge straight into the output.
+
+// DefaultMemoryBudget is how many bytes of lines ExternalSort will hold in memory
+// at once if you pass a budget of zero or less.
+const DefaultMemoryBudget = 64 << 20
+
+// Every string in a slice costs its header (a pointer and a length) on top of the
+// actual bytes, so count that against the memory budget too.
+const lineOverhead = 16
+
+// maxFanIn is the most runs that get merged (and so opened) at once.
+const maxFanIn = 64
+
+// ExternalSort reads newline-delimited lines from in, sorts them with cmp and writes
+// them to out, one per line. It holds roughly memBudget bytes of lines in memory at
+// a time and spills anything beyond that to temp files, which get cleaned up before
+// it returns. Like the rest of the package, it's stable.
+func ExternalSort(in io.Reader, out io.Writer, memBudget int, cmp func(a, b string) int) error {
+	return ExternalSortCtx(context.Background(), in, out, memBudget, cmp, nil)
+}
+
+// ExternalSortCtx is ExternalSort, but it gives up and returns ctx.Err() if ctx
+// is cancelled partway through. The temp files still get cleaned up either way.
+//
+// If progress isn't nil, it gets called every so often with how many lines have
+// been read from in and how many have been written to out. Nothing gets written
+// until all of the input has been read, so once written is above zero, read is
+// the total and written/read is how far along it is. The last call is always
+// written == read.
+func ExternalSortCtx(ctx context.Context, in io.Reader, out io.Writer, memBudget int, cmp func(a, b string) int, progress func(read, written int)) error {
+	if progress == nil {
+		progress = func(read, written int) {}
+	}
+	if memBudget <= 0 {
+		memBudget = DefaultMemoryBudget
+	}
+
+	// All of the runs go in their own temp directory, so cleaning up is just a
+	// matter of deleting the directory when we're done, no matter how we leave.
+	tmpDir, err := os.MkdirTemp("", "mergesort-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var runs []string
+	var lines []string
+	var scratch []string
+	size := 0
+	read := 0
+
+	reader := bufio.NewReader(in)
+	for {
+		// Every so often, make sure nobody has told us to stop.
+		if read%progressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			progress(read, 0)
+		}
+
+		// ReadString doesn't have a line length limit like bufio.Scanner does,
+		// so really long lines won't trip us up.
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(line, "\n")
+			lines = append(lines, line)
+			size += len(line) + lineOverhead
+			read++
+		}
+
+		if size >= memBudget {
+			run, err := writeRun(ctx, tmpDir, lines, &scratch, cmp)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, run)
+
+			// reuse the same backing array for the next chunk
+			lines = lines[:0]
+			size = 0
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// If nothing got spilled, everything fit in memory and we can just sort it
+	// and write it straight out.
+	if len(runs) == 0 {
+		if err := mergeSortCtx(ctx, lines, scratch, cmp, nil); err != nil {
+			return err
+		}
+		progress(read, 0)
+		if err := writeLines(out, lines); err != nil {
+			return err
+		}
+		progress(read, read)
+		return nil
+	}
+
+	// Otherwise whatever is left over becomes the last run.
+	if len(lines) > 0 {
+		run, err := writeRun(ctx, tmpDir, lines, &scratch, cmp)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+	}
+	progress(read, 0)
+
+	return mergeRuns(ctx, tmpDir, runs, out, cmp, func(written int) {
+		progress(read, written)
+	})
+}
+
+// ExternalSortFile is ExternalSort for when you just have a couple of file paths.
+func ExternalSortFile(inPath, outPath string, memBudget int, cmp func(a, b string) int) error {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+
+	if err := ExternalSort(in, out, memBudget, cmp); err != nil {
+		out.Close()
+		return err
+	}
+
+	// Don't just defer this one, since a failed Close on a file we wrote to can
+	// mean the data never made it to disk.
+	return out.Close()
+}
+
+// writeRun sorts lines and writes them out to a new temp file in dir, returning
+// the path to it. The scratch buffer is kept around between calls so we're not
+// making a new one for every run.
+func writeRun(ctx context.Context, dir string, lines []string, scratch *[]string, cmp func(a, b string) int) (string, error) {
+	if len(*scratch) < len(lines) {
+		*scratch = make([]string, len(lines))
+	}
+	if err := mergeSortCtx(ctx, lines, *scratch, cmp, nil); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, "run-*")
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeLines(f, lines); err != nil {
+		f.Close()
+		return "", err
+	}
+
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// writeLines writes each line followed by a newline through a buffered writer.
+func writeLines(out io.Writer, lines []string) error {
+	w := bufio.NewWriter(out)
+	for _, line := range lines {
+		if _, err := w.WriteString(line); err != nil {
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// mergeRuns merges all of the runs into out, maxFanIn at a time. Each pass merges
+// neighbouring groups of runs into one bigger run, so the order the runs came in
+// (and with it, stability) is kept the whole way through. Only the last merge
+// writes to out, so that's the only one that calls progress.
+func mergeRuns(ctx context.Context, dir string, runs []string, out io.Writer, cmp func(a, b string) int, progress func(written int)) error {
+	for len(runs) > maxFanIn {
+		var merged []string
+		for start := 0; start < len(runs); start += maxFanIn {
+			group := runs[start:min(start+maxFanIn, len(runs))]
+			run, err := mergeToRun(ctx, dir, group, cmp)
+			if err != nil {
+				return err
+			}
+			merged = append(merged, run)
+		}
+		runs = merged
+	}
+
+	return mergeGroup(ctx, runs, out, cmp, progress)
+}
+
+// mergeToRun merges a group of runs into a new run in dir and returns the path to
+// it. The old runs get deleted as soon as they're merged, so we're never using
+// much more disk than the size of the input.
+func mergeToRun(ctx context.Context, dir string, runs []string, cmp func(a, b string) int) (string, error) {
+	f, err := os.CreateTemp(dir, "run-*")
+	if err != nil {
+		return "", err
+	}
+
+	if err := mergeGroup(ctx, runs, f, cmp, nil); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	for _, run := range runs {
+		if err := os.Remove(run); err != nil {
+			return "", err
+		}
+	}
+	return f.Name(), nil
+}
+
+// mergeGroup opens every run it's given and merges them all into out with
+// MergeKSeq. Callers make sure there are never more than maxFanIn of them. If
+// progress isn't nil, it gets told how many lines have been written so far.
+func mergeGroup(ctx context.Context, runs []string, out io.Writer, cmp func(a, b string) int, progress func(written int)) error {
+	seqs := make([]iter.Seq[string], len(runs))
+	errs := make([]error, len(runs))
+	for i, path := range runs {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		seqs[i] = runLines(path, bufio.NewReader(f), &errs[i])
+	}
+
+	// MergeKSeq breaks ties by which input came first, and runs are always in
+	// the order they appeared in the input, so this keeps the whole sort stable.
+	w := bufio.NewWriter(out)
+	written := 0
+	for line := range MergeKSeq(seqs, cmp) {
+		written++
+		if written%progressEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if progress != nil {
+				progress(written)
+			}
+		}
+
+		if _, err := w.WriteString(line); err != nil {
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	// A run that failed partway through just looks like it ran out of lines to
+	// the merge, so check whether any of them actually did.
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if progress != nil {
+		progress(written)
+	}
+	return nil
+}
+
+// runLines reads a run back one line at a time. An iterator has no way to return
+// an error, so if reading fails it stops early and leaves the error in *errp.
+func runLines(path string, r *bufio.Reader, errp *error) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			line, err := r.ReadString('\n')
+			if err == io.EOF {
+				// writeLines always ends with a newline, so anything here at
+				// EOF is just the empty remainder after it.
+				return
+			}
+			if err != nil {
+				*errp = fmt.Errorf("reading run %s: %w", path, err)
+				return
+			}
+			if !yield(strings.TrimSuffix(line, "\n")) {
+				return
+			}
+		}
+	}
+}
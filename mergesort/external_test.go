@@ -0,0 +1,175 @@
+package mergesort
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// expectSorted is what ExternalSort should write for in: every line, sorted stably
+// with cmp, each one ending in a newline (even if the last one didn't in the input).
+func expectSorted(in string, cmp func(a, b string) int) string {
+	if in == "" {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(in, "\n"), "\n")
+	slices.SortStableFunc(lines, cmp)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// byFirstByte only looks at the first byte of each line, so lines that start the
+// same are ties and have to come out in the order they went in.
+func byFirstByte(a, b string) int {
+	return strings.Compare(a[:min(1, len(a))], b[:min(1, len(b))])
+}
+
+func TestExternalSort(t *testing.T) {
+	// Lots of lines, so a tiny budget makes way more than maxFanIn runs and the
+	// merge has to take more than one pass.
+	var many strings.Builder
+	r := rand.New(rand.NewSource(1))
+	for i := range 5000 {
+		fmt.Fprintf(&many, "%c%d\n", 'a'+r.Intn(26), i)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"one line", "hello\n"},
+		{"no trailing newline", "pear\napple\nfig"},
+		{"empty lines", "b\n\na\n\n\nc\n"},
+		{"only empty lines", "\n\n\n"},
+		{"ties", "b2\na1\nb1\na2\nb3\na3\n"},
+		{"many runs", many.String()},
+	}
+
+	for _, tt := range tests {
+		for _, budget := range []int{1, 64, 0} {
+			t.Run(fmt.Sprintf("%s/budget=%d", tt.name, budget), func(t *testing.T) {
+				// Point the temp files somewhere we can check gets cleaned up.
+				tmp := t.TempDir()
+				t.Setenv("TMPDIR", tmp)
+
+				var out strings.Builder
+				if err := ExternalSort(strings.NewReader(tt.in), &out, budget, byFirstByte); err != nil {
+					t.Fatal(err)
+				}
+				if want := expectSorted(tt.in, byFirstByte); out.String() != want {
+					t.Errorf("ExternalSort(%q) = %q, want %q", tt.in, out.String(), want)
+				}
+
+				if left, _ := os.ReadDir(tmp); len(left) != 0 {
+					t.Errorf("%d temp files left behind", len(left))
+				}
+			})
+		}
+	}
+}
+
+func TestExternalSortFile(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	out := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(in, []byte("c\nb\na"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExternalSortFile(in, out, 1, strings.Compare); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "a\nb\nc\n" {
+		t.Errorf("got %q, want %q", got, "a\nb\nc\n")
+	}
+}
+
+func TestExternalSortCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out strings.Builder
+	err := ExternalSortCtx(ctx, strings.NewReader("b\na\nc\n"), &out, 1, strings.Compare, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExternalSortCtx with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestExternalSortProgress(t *testing.T) {
+	// More lines than progressEvery, so there's more than one report while
+	// reading and while writing.
+	var in strings.Builder
+	r := rand.New(rand.NewSource(1))
+	const lines = 3 * progressEvery
+	for range lines {
+		fmt.Fprintf(&in, "%d\n", r.Intn(1000))
+	}
+
+	// A budget that spills to runs, and one where it all fits in memory.
+	for _, budget := range []int{1 << 20, 0} {
+		t.Run(fmt.Sprintf("budget=%d", budget), func(t *testing.T) {
+			var calls [][2]int
+			var out strings.Builder
+			err := ExternalSortCtx(context.Background(), strings.NewReader(in.String()), &out, budget, strings.Compare, func(read, written int) {
+				calls = append(calls, [2]int{read, written})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != expectSorted(in.String(), strings.Compare) {
+				t.Fatal("output isn't sorted")
+			}
+
+			if len(calls) < 4 {
+				t.Fatalf("only %d progress calls: %v", len(calls), calls)
+			}
+			for i, c := range calls {
+				if c[1] > 0 && c[0] != lines {
+					t.Errorf("call %d: wrote %d before reading everything (%d)", i, c[1], c[0])
+				}
+				if i > 0 && (c[0] < calls[i-1][0] || c[1] < calls[i-1][1]) {
+					t.Errorf("call %d went backwards from %v to %v", i, calls[i-1], c)
+				}
+			}
+			if last := calls[len(calls)-1]; last != [2]int{lines, lines} {
+				t.Errorf("last call was %v, want [%d %d]", last, lines, lines)
+			}
+		})
+	}
+}
+
+// The input here is way shorter than progressEvery, so the only places left to
+// notice the cancel are the sorts of each run.
+func TestExternalSortCancelledWhileSorting(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	var in strings.Builder
+	for i := range 1000 {
+		fmt.Fprintf(&in, "%d\n", 1000-i)
+	}
+
+	for _, budget := range []int{1 << 10, 0} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelling := func(a, b string) int {
+			cancel()
+			return strings.Compare(a, b)
+		}
+
+		var out strings.Builder
+		err := ExternalSortCtx(ctx, strings.NewReader(in.String()), &out, budget, cancelling, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("budget=%d: got %v, want context.Canceled", budget, err)
+		}
+		cancel()
+	}
+}
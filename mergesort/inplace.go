@@ -0,0 +1,77 @@
+package mergesort
+
+import "cmp"
+
+// The MergeSortFunc version is nice to read, but it's pretty wasteful. Every call to
+// merge builds a brand new slice with append (which has to keep growing it), so if
+// you sort a million items you're making garbage for the garbage collector the
+// whole way down. If you're sorting a lot of stuff, that shows up.
+//
+// The fix is to sort the slice in place and only ever use ONE extra slice as
+// scratch space. When it's time to merge two halves, we copy the left half into the
+// scratch buffer and then merge the buffer and the right half back into the
+// original slice. That's safe because we're always writing at or behind the spot we
+// read the right half from, so we never stomp on something we haven't looked at yet.
+
+// MergeSortInPlace is the in-place version of MergeSort. See MergeSortInPlaceFunc.
+func MergeSortInPlace[T cmp.Ordered](u []T, buf []T) {
+	MergeSortInPlaceFunc(u, buf, cmp.Compare[T])
+}
+
+// MergeSortInPlaceFunc sorts u in place using cmp, exactly like MergeSortFunc
+// would (stable and all), but without building new slices along the way. The only
+// extra memory it needs is a scratch buffer of at least len(u)/2 items. You can
+// pass one in with buf, which is handy if you sort a bunch of slices and want to
+// reuse the same buffer for all of them. If buf is nil or too small, we'll just
+// make one ourselves.
+func MergeSortInPlaceFunc[T any](u []T, buf []T, cmp func(a, b T) int) {
+	if len(buf) < len(u)/2 {
+		buf = make([]T, len(u)/2)
+	}
+	mergeSortInPlace(u, buf, cmp)
+}
+
+func mergeSortInPlace[T any](u []T, buf []T, cmp func(a, b T) int) {
+	// Same base case as always, nothing to do here.
+	if len(u) <= 1 {
+		return
+	}
+
+	half := len(u) / 2
+	mergeSortInPlace(u[:half], buf, cmp)
+	mergeSortInPlace(u[half:], buf, cmp)
+
+	mergeInPlace(u, half, buf, cmp)
+}
+
+// mergeInPlace merges the two sorted runs u[:half] and u[half:] back into u.
+func mergeInPlace[T any](u []T, half int, buf []T, cmp func(a, b T) int) {
+	// If the last item on the left already comes before the first item on the
+	// right, the whole thing is already in order and we can skip the work.
+	if cmp(u[half-1], u[half]) <= 0 {
+		return
+	}
+
+	// Stash the left half in the scratch buffer so we can overwrite it.
+	left := buf[:half]
+	copy(left, u[:half])
+
+	// i walks the left half (now in buf), j walks the right half (still in u),
+	// and k is where the next item gets written to.
+	i, j, k := 0, half, 0
+	for i < len(left) && j < len(u) {
+		// Just like merge, taking the left item on a tie keeps this stable.
+		if cmp(left[i], u[j]) <= 0 {
+			u[k] = left[i]
+			i++
+		} else {
+			u[k] = u[j]
+			j++
+		}
+		k++
+	}
+
+	// If anything is left over from the left half, copy it to the end. If the
+	// right half has leftovers, they're already sitting where they belong.
+	copy(u[k:], left[i:])
+}
@@ -0,0 +1,60 @@
+package mergesort
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestMergeSortInPlaceFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// A buffer that's too small on purpose some of the time, to make sure it
+	// gets replaced instead of overrun.
+	var buf []record
+	for range 500 {
+		keys := make([]int, r.Intn(200))
+		for i := range keys {
+			keys[i] = r.Intn(10)
+		}
+
+		got := records(keys...)
+		want := slices.Clone(got)
+		slices.SortStableFunc(want, byKey)
+
+		MergeSortInPlaceFunc(got, buf, byKey)
+		if !slices.Equal(got, want) {
+			t.Fatalf("MergeSortInPlaceFunc(%v)\n got %v\nwant %v", keys, got, want)
+		}
+		buf = make([]record, r.Intn(100))
+	}
+
+	// and nil shouldn't blow up
+	MergeSortInPlace([]int(nil), nil)
+}
+
+// These two are the ones to compare with -benchmem (or just look at allocs/op,
+// since ReportAllocs turns that on). The in-place one reuses a single buffer for
+// every sort, so the loop doesn't allocate anything at all.
+func BenchmarkMergeSortInPlace(b *testing.B) {
+	data := randomInts(benchSize)
+	u := make([]int, len(data))
+	buf := make([]int, len(data)/2)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		copy(u, data)
+		MergeSortInPlace(u, buf)
+	}
+}
+
+func BenchmarkMergeSortAllocs(b *testing.B) {
+	data := randomInts(benchSize)
+	u := make([]int, len(data))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		copy(u, data)
+		MergeSort(u)
+	}
+}
@@ -1,15 +1,45 @@
 package mergesort
 
+import "cmp"
+
 // You can probably just use the standard sort libraries to do things better, but
 // I just love the simplicity of setting up a merge sort with whatever you want
 // to sort a list by. It's recursive, and that's all I need to hear when using it.
 // Recursive functions are OP.
 
-func MergeSort(u []int) []int {
-	// You can substitute an integer for whatever you want to search by.
-	// I'm using integers here for simplicity's sake.
-	if len(u) == 1 {
-		return u // If we're ever just one item, just return it.
+// MergeSort sorts anything that can be compared with < in ascending order. This
+// is just a convenience wrapper around MergeSortFunc using cmp.Compare, which
+// works for ints, floats, strings, and anything else that satisfies cmp.Ordered.
+// Like MergeSortFunc, it's stable.
+func MergeSort[T cmp.Ordered](u []T) []T {
+	return MergeSortFunc(u, cmp.Compare[T])
+}
+
+// MergeSortFunc is where the real work happens. Instead of hard-coding how two
+// items are ordered, the caller hands us a comparator function that follows the
+// same convention as cmp.Compare and slices.SortFunc:
+//
+//	cmp(a, b) < 0   if a should come before b
+//	cmp(a, b) == 0  if it doesn't matter
+//	cmp(a, b) > 0   if a should come after b
+//
+// The sort is stable: items the comparator considers equal come out in the
+// same order they went in. This is what lets you sort by one key and then by
+// another without the second sort scrambling the first.
+//
+// Since the ordering is up to you, you can sort a slice of structs by whatever
+// field you want, like so:
+//
+//	MergeSortFunc(games, func(a, b Game) int {
+//		return cmp.Compare(a.YearCreated, b.YearCreated)
+//	})
+func MergeSortFunc[T any](u []T, cmp func(a, b T) int) []T {
+	// If we're ever just one item, just return it. The same goes for an
+	// empty (or nil) slice, which is already as sorted as it'll ever be.
+	// Don't leave that case out! Splitting an empty slice in half gives
+	// you two more empty slices, and we'd recurse until the stack blows.
+	if len(u) <= 1 {
+		return u
 	} else {
 		// first, compute what half the size of the provided array is.
 		// This will be a whole number, no remainders.
@@ -22,20 +52,20 @@ func MergeSort(u []int) []int {
 
 		// Now let's call the function again, remember this will
 		// keep recursively repeating until it hits just one item.
-		lsort := MergeSort(left)
-		rsort := MergeSort(right)
+		lsort := MergeSortFunc(left, cmp)
+		rsort := MergeSortFunc(right, cmp)
 
 		// Finally, let the helper function do the heavy lifting of
 		// deciding the order of the items.
-		return merge(lsort, rsort)
+		return merge(lsort, rsort, cmp)
 	}
 }
 
 // Note lowercase "merge", this is because this function should be
 // considered internal. No need to call this from anywhere else.
-func merge(left, right []int) []int {
+func merge[T any](left, right []T, cmp func(a, b T) int) []T {
 	// create a blank return value
-	var retval []int
+	var retval []T
 
 	for {
 		// We're going to loop until we break on a condition.
@@ -45,15 +75,17 @@ func merge(left, right []int) []int {
 		}
 
 		// this is where we decide _how_ this is going to be
-		// ordered. For this, I'm just going go by ascending
-		// order, so if left < right, push left onto the retval
-		if left[0] < right[0] {
+		// ordered. We don't actually know anymore, that's up to
+		// the comparator. If it says left comes first OR that
+		// they're equal, push left onto the retval. Taking the
+		// left one on a tie is what keeps the sort stable, since
+		// everything in left started out before everything in right.
+		if cmp(left[0], right[0]) <= 0 {
 			retval = append(retval, left[0])
 			// don't forget to pop off the left value
 			left = left[1:]
 		} else {
-			// otherwise, right[0] is greater than left[0], or
-			// they're equal in which case it doesn't matter anyway.
+			// otherwise, right[0] strictly comes before left[0].
 			retval = append(retval, right[0])
 			right = right[1:]
 		}
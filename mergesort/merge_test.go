@@ -0,0 +1,119 @@
+package mergesort
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// record is something to sort where two items can have the same key but still be
+// told apart. idx is where it started out, so after sorting we can check that
+// everything with the same key is still in its original order.
+type record struct {
+	key int
+	idx int
+}
+
+func byKey(a, b record) int {
+	return cmp.Compare(a.key, b.key)
+}
+
+// records turns a list of keys into records numbered in order.
+func records(keys ...int) []record {
+	retval := make([]record, len(keys))
+	for i, k := range keys {
+		retval[i] = record{key: k, idx: i}
+	}
+	return retval
+}
+
+func TestMergeSortFuncStable(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []int
+	}{
+		{"all equal", []int{7, 7, 7, 7, 7}},
+		{"two keys", []int{1, 0, 1, 0, 1, 0}},
+		{"already sorted", []int{0, 0, 1, 1, 2, 2}},
+		{"reversed", []int{2, 2, 1, 1, 0, 0}},
+		{"ties across the split", []int{3, 1, 2, 1, 3, 2, 1}},
+		{"one item", []int{4}},
+		{"two equal", []int{5, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := records(tt.keys...)
+			want := slices.Clone(in)
+			slices.SortStableFunc(want, byKey)
+
+			got := MergeSortFunc(slices.Clone(in), byKey)
+			if !slices.Equal(got, want) {
+				t.Errorf("MergeSortFunc(%v) = %v, want %v", in, got, want)
+			}
+		})
+	}
+}
+
+// Lots of random slices with a small range of keys, so there are plenty of ties.
+// slices.SortStableFunc is the reference answer.
+func TestMergeSortFuncStableRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for range 500 {
+		keys := make([]int, r.Intn(200))
+		for i := range keys {
+			keys[i] = r.Intn(10)
+		}
+
+		in := records(keys...)
+		want := slices.Clone(in)
+		slices.SortStableFunc(want, byKey)
+
+		got := MergeSortFunc(slices.Clone(in), byKey)
+		if !slices.Equal(got, want) {
+			t.Fatalf("MergeSortFunc(%v)\n got %v\nwant %v", in, got, want)
+		}
+	}
+}
+
+// Don't forget the base case! An empty or nil slice used to recurse forever.
+func TestMergeSortEmpty(t *testing.T) {
+	if got := MergeSort([]int(nil)); len(got) != 0 {
+		t.Errorf("MergeSort(nil) = %v, want empty", got)
+	}
+	if got := MergeSort([]int{}); len(got) != 0 {
+		t.Errorf("MergeSort([]int{}) = %v, want empty", got)
+	}
+	if got := MergeSortFunc([]string(nil), cmp.Compare[string]); len(got) != 0 {
+		t.Errorf("MergeSortFunc(nil) = %v, want empty", got)
+	}
+}
+
+func TestMergeSortStrings(t *testing.T) {
+	got := MergeSort([]string{"pear", "apple", "fig", "apple"})
+	want := []string{"apple", "apple", "fig", "pear"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeSort = %v, want %v", got, want)
+	}
+}
+
+// FuzzMergeSort checks MergeSort against slices.Sort for whatever bytes the fuzzer
+// comes up with. Run it for real with: go test -fuzz=FuzzMergeSort
+func FuzzMergeSort(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{1})
+	f.Add([]byte{3, 1, 2})
+	f.Add([]byte{5, 5, 5, 0, 255})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		want := slices.Clone(data)
+		slices.Sort(want)
+
+		got := MergeSort(slices.Clone(data))
+		if !slices.Equal(got, want) {
+			t.Errorf("MergeSort(%v) = %v, want %v", data, got, want)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package mergesort
+
+import (
+	"container/heap"
+	"iter"
+	"slices"
+)
+
+// merge only knows how to combine two sorted lists, but there's no reason to stop
+// at two. If you've got a pile of lists that are each already sorted (results from
+// different workers, different files, whatever), you can merge all of them at once
+// by always taking the smallest item from the front of any of them. Finding that
+// smallest item is exactly what a min-heap is good at, so with k lists each step
+// costs log(k) instead of looking at the front of every single list.
+
+// MergeK merges any number of already-sorted slices into one sorted slice using cmp.
+// If two items are equal, the one from the earlier slice comes first.
+func MergeK[T any](lists [][]T, cmp func(a, b T) int) []T {
+	total := 0
+	seqs := make([]iter.Seq[T], len(lists))
+	for i, list := range lists {
+		total += len(list)
+		seqs[i] = slices.Values(list)
+	}
+
+	// We know exactly how big the result is going to be, so make room for all
+	// of it up front instead of letting append keep growing it.
+	retval := make([]T, 0, total)
+	for v := range MergeKSeq(seqs, cmp) {
+		retval = append(retval, v)
+	}
+	return retval
+}
+
+// MergeKSeq is the streaming version of MergeK. It takes any number of sorted
+// iterators and returns a single sorted iterator, pulling only one item at a time
+// from each input. Nothing gets collected into memory, so this works just as well
+// on streams that are way too big to hold all at once.
+func MergeKSeq[T any](seqs []iter.Seq[T], cmp func(a, b T) int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		h := &seqHeap[T]{cmp: cmp}
+
+		// iter.Pull turns a "push" style iterator (the kind you range over) into a
+		// "pull" style one, where we call next() whenever we want another item.
+		// That's what we need here, since we only want to advance whichever input
+		// just had its item taken. Every Pull has to be stopped, even if we quit
+		// early, so defer those.
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+
+			if v, ok := next(); ok {
+				h.heads = append(h.heads, &seqHead[T]{val: v, idx: i, next: next})
+			}
+		}
+		heap.Init(h)
+
+		for h.Len() > 0 {
+			// The smallest item out of every input is always at the top.
+			top := h.heads[0]
+			if !yield(top.val) {
+				return
+			}
+
+			// Advance that input. If it's empty, take it off the heap,
+			// otherwise let the heap move it to wherever it goes now.
+			if v, ok := top.next(); ok {
+				top.val = v
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}
+}
+
+// seqHead is the item currently at the front of one of the inputs.
+type seqHead[T any] struct {
+	val  T
+	idx  int
+	next func() (T, bool)
+}
+
+// seqHeap implements heap.Interface over the front of each input.
+type seqHeap[T any] struct {
+	heads []*seqHead[T]
+	cmp   func(a, b T) int
+}
+
+func (h *seqHeap[T]) Len() int { return len(h.heads) }
+
+func (h *seqHeap[T]) Less(i, j int) bool {
+	c := h.cmp(h.heads[i].val, h.heads[j].val)
+	if c != 0 {
+		return c < 0
+	}
+	// Break ties by which input it came from so the merge is stable.
+	return h.heads[i].idx < h.heads[j].idx
+}
+
+func (h *seqHeap[T]) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *seqHeap[T]) Push(x any) { h.heads = append(h.heads, x.(*seqHead[T])) }
+
+func (h *seqHeap[T]) Pop() any {
+	old := h.heads
+	n := len(old)
+	head := old[n-1]
+	h.heads = old[:n-1]
+	return head
+}
@@ -0,0 +1,95 @@
+package mergesort
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"testing"
+)
+
+func TestMergeK(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]int
+		want  []int
+	}{
+		{"nil", nil, []int{}},
+		{"no lists", [][]int{}, []int{}},
+		{"all empty", [][]int{nil, {}, nil}, []int{}},
+		{"one list", [][]int{{1, 2, 3}}, []int{1, 2, 3}},
+		{"some empty", [][]int{nil, {2, 4}, {}, {1, 3}}, []int{1, 2, 3, 4}},
+		{"different lengths", [][]int{{5}, {1, 2, 3, 9}, {4, 6}}, []int{1, 2, 3, 4, 5, 6, 9}},
+		{"duplicates", [][]int{{1, 1, 2}, {1, 2, 2}}, []int{1, 1, 1, 2, 2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeK(tt.lists, cmp.Compare[int])
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("MergeK(%v) = %v, want %v", tt.lists, got, tt.want)
+			}
+		})
+	}
+}
+
+// When items from different lists tie, the one from the earlier list has to come
+// out first. Numbering the records across all the lists in order means the right
+// answer is just a stable sort of everything glued together.
+func TestMergeKStable(t *testing.T) {
+	all := records(0, 1, 1, 2, 0, 1, 2, 2, 0, 0, 1, 1, 2)
+	lists := [][]record{all[0:4], all[4:8], all[8:10], all[10:13]}
+
+	want := slices.Clone(all)
+	slices.SortStableFunc(want, byKey)
+
+	got := MergeK(lists, byKey)
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeK() = %v, want %v", got, want)
+	}
+}
+
+// counted wraps a slice in an iterator that keeps track of whether it's still
+// running, so we can tell if MergeKSeq left any of its iter.Pulls hanging.
+func counted(list []int, running *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		*running++
+		defer func() { *running-- }()
+		for _, v := range list {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func TestMergeKSeqBreak(t *testing.T) {
+	running := 0
+	seqs := []iter.Seq[int]{
+		counted([]int{1, 4, 7}, &running),
+		counted([]int{2, 5, 8}, &running),
+		counted([]int{3, 6, 9}, &running),
+	}
+
+	var got []int
+	for v := range MergeKSeq(seqs, cmp.Compare[int]) {
+		got = append(got, v)
+		if len(got) == 4 {
+			break
+		}
+	}
+
+	if !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("got %v, want [1 2 3 4]", got)
+	}
+	if running != 0 {
+		t.Errorf("%d inputs still running after break", running)
+	}
+
+	// Running it all the way through should leave nothing behind either.
+	if got := slices.Collect(MergeKSeq(seqs, cmp.Compare[int])); len(got) != 9 {
+		t.Errorf("got %v, want all 9", got)
+	}
+	if running != 0 {
+		t.Errorf("%d inputs still running after finishing", running)
+	}
+}
@@ -0,0 +1,120 @@
+package mergesort
+
+import "cmp"
+
+// Regular merge sort is kind of dumb about data that's already (mostly) sorted. It
+// splits everything down to single items no matter what, then builds it all back
+// up, so a slice that was sorted to begin with costs just as much as a random one.
+//
+// A "natural" merge sort skips the splitting part. Instead, it walks the slice
+// looking for stretches that are already in order, called runs, and then merges
+// neighbouring runs together until there's only one left. On a sorted slice the
+// whole thing is one run, so we look at every item once and we're done. A slice
+// that's in reverse order is also just one run, we just have to flip it first.
+// This is the same trick TimSort (Python's sort) uses.
+
+// NaturalMergeSort is the adaptive version of MergeSortInPlace.
+func NaturalMergeSort[T cmp.Ordered](u []T) {
+	NaturalMergeSortFunc(u, cmp.Compare[T])
+}
+
+// NaturalMergeSortFunc sorts u in place with cmp, taking advantage of any runs that
+// are already in ascending or descending order. Sorted input (either way) is O(N).
+// On random input there are so many tiny runs that it's no faster than
+// MergeSortInPlaceFunc and needs about three times the memory, so it's only worth it
+// when the data is mostly in order already. It's stable, too.
+func NaturalMergeSortFunc[T any](u []T, cmp func(a, b T) int) {
+	runs := findRuns(u, cmp)
+
+	// Only one run means it's already sorted, no need to even make a buffer.
+	if len(runs) <= 2 {
+		return
+	}
+
+	// A run can be pretty much any length, so unlike MergeSortInPlaceFunc the
+	// left side of a merge could be almost the whole slice.
+	buf := make([]T, len(u))
+
+	// runs holds the index where each run starts, plus len(u) on the end so
+	// that run i is always u[runs[i]:runs[i+1]]. Each pass merges runs in pairs,
+	// which halves the number of runs, until there's only one left. The merged
+	// runs get written back over the front of runs itself, which is safe because
+	// we're always writing behind where we're reading.
+	for len(runs) > 2 {
+		n := 1
+		for i := 0; i+1 < len(runs); i += 2 {
+			lo := runs[i]
+			if i+2 < len(runs) {
+				mid, hi := runs[i+1], runs[i+2]
+				mergeInPlace(u[lo:hi], mid-lo, buf, cmp)
+				runs[n] = hi
+			} else {
+				// Odd one out, it just waits for the next pass.
+				runs[n] = runs[i+1]
+			}
+			n++
+		}
+		runs = runs[:n]
+	}
+}
+
+// findRuns returns the starting index of every run in u, followed by len(u).
+// Descending runs are reversed on the spot so every run is ascending afterwards.
+func findRuns[T any](u []T, cmp func(a, b T) int) []int {
+	// Random data has about one run for every two items, and letting append
+	// grow the slice that far would allocate it several times over. So count
+	// them first and make it exactly the right size. Where runs end doesn't
+	// depend on reversing the earlier ones, so the count always matches.
+	count := 0
+	for i := 0; i < len(u); count++ {
+		i = runEnd(u, i, cmp)
+	}
+
+	// That's two trips over the slice though, which would make sorted input
+	// (the case this is for) twice as slow. If it's all one run, the first
+	// trip already told us everything.
+	if count == 1 {
+		if len(u) > 1 && cmp(u[1], u[0]) < 0 {
+			reverse(u)
+		}
+		return []int{0, len(u)}
+	}
+
+	runs := make([]int, 1, count+1)
+	for i := 0; i < len(u); {
+		j := runEnd(u, i, cmp)
+		if j > i+1 && cmp(u[i+1], u[i]) < 0 {
+			reverse(u[i:j])
+		}
+		runs = append(runs, j)
+		i = j
+	}
+
+	return runs
+}
+
+// runEnd returns where the run starting at u[i] ends.
+func runEnd[T any](u []T, i int, cmp func(a, b T) int) int {
+	j := i + 1
+	if j < len(u) && cmp(u[j], u[j-1]) < 0 {
+		// Descending run. This has to be _strictly_ descending, because
+		// reversing two equal items would swap their order and we'd lose
+		// stability.
+		for j < len(u) && cmp(u[j], u[j-1]) < 0 {
+			j++
+		}
+	} else {
+		// Ascending run, equal items are fine here.
+		for j < len(u) && cmp(u[j], u[j-1]) >= 0 {
+			j++
+		}
+	}
+	return j
+}
+
+// reverse flips u around in place.
+func reverse[T any](u []T) {
+	for i, j := 0, len(u)-1; i < j; i, j = i+1, j-1 {
+		u[i], u[j] = u[j], u[i]
+	}
+}
@@ -0,0 +1,85 @@
+package mergesort
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestNaturalMergeSortFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for range 500 {
+		keys := make([]int, r.Intn(200))
+		for i := range keys {
+			keys[i] = r.Intn(10)
+		}
+		// Sort some of them first, one way or the other, so there are long runs
+		// to find and not just random ones.
+		switch r.Intn(3) {
+		case 1:
+			slices.Sort(keys[:len(keys)/2])
+		case 2:
+			slices.Sort(keys)
+			slices.Reverse(keys)
+		}
+
+		got := records(keys...)
+		want := slices.Clone(got)
+		slices.SortStableFunc(want, byKey)
+
+		NaturalMergeSortFunc(got, byKey)
+		if !slices.Equal(got, want) {
+			t.Fatalf("NaturalMergeSortFunc(%v)\n got %v\nwant %v", keys, got, want)
+		}
+	}
+
+	NaturalMergeSort([]int(nil))
+}
+
+// The three kinds of input the natural sort is supposed to be good at. Perturbed is
+// sorted, with 1% of the items swapped with some other random item.
+func sortedInts(n int) []int {
+	retval := randomInts(n)
+	slices.Sort(retval)
+	return retval
+}
+
+func reversedInts(n int) []int {
+	retval := sortedInts(n)
+	slices.Reverse(retval)
+	return retval
+}
+
+func perturbedInts(n int) []int {
+	r := rand.New(rand.NewSource(7))
+	retval := sortedInts(n)
+	for range n / 100 {
+		i, j := r.Intn(n), r.Intn(n)
+		retval[i], retval[j] = retval[j], retval[i]
+	}
+	return retval
+}
+
+// benchmarkSort times sort on a fresh copy of data every time around.
+func benchmarkSort(b *testing.B, data []int, sort func([]int)) {
+	u := make([]int, len(data))
+	b.ResetTimer()
+	for range b.N {
+		copy(u, data)
+		sort(u)
+	}
+}
+
+func naturalSort(u []int) { NaturalMergeSort(u) }
+func inPlaceSort(u []int) { MergeSortInPlace(u, nil) }
+
+func BenchmarkNaturalSorted(b *testing.B)    { benchmarkSort(b, sortedInts(benchSize), naturalSort) }
+func BenchmarkNaturalReversed(b *testing.B)  { benchmarkSort(b, reversedInts(benchSize), naturalSort) }
+func BenchmarkNaturalPerturbed(b *testing.B) { benchmarkSort(b, perturbedInts(benchSize), naturalSort) }
+func BenchmarkNaturalRandom(b *testing.B)    { benchmarkSort(b, randomInts(benchSize), naturalSort) }
+
+func BenchmarkInPlaceSorted(b *testing.B)    { benchmarkSort(b, sortedInts(benchSize), inPlaceSort) }
+func BenchmarkInPlaceReversed(b *testing.B)  { benchmarkSort(b, reversedInts(benchSize), inPlaceSort) }
+func BenchmarkInPlacePerturbed(b *testing.B) { benchmarkSort(b, perturbedInts(benchSize), inPlaceSort) }
+func BenchmarkInPlaceRandom(b *testing.B)    { benchmarkSort(b, randomInts(benchSize), inPlaceSort) }
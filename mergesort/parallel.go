@@ -0,0 +1,83 @@
+package mergesort
+
+import (
+	"cmp"
+	"runtime"
+	"sync"
+)
+
+// Merge sort is pretty much begging to be done concurrently. Every time we split
+// the slice in half, the two halves have absolutely nothing to do with each other
+// until it's time to merge them back together. So why not sort them at the same
+// time? Same idea as the worker fan-out in the channels module.
+//
+// There are two catches though. First, spinning up a goroutine isn't free, so
+// doing it for a slice of 3 items is slower than just sorting the thing. That's
+// what the threshold is for: below it, we just fall back to the regular old
+// MergeSortFunc. Second, spinning up a goroutine for every single split on a huge
+// slice would create way more goroutines than we have CPUs to run them on, so we
+// hand out a limited number of tokens and only go concurrent when we can get one.
+
+// DefaultParallelThreshold is the slice length below which ParallelMergeSort stops
+// splitting work off into goroutines. It's used whenever you pass a threshold of
+// zero or less.
+const DefaultParallelThreshold = 8192
+
+// ParallelMergeSort is the concurrent version of MergeSort.
+func ParallelMergeSort[T cmp.Ordered](u []T, threshold int) []T {
+	return ParallelMergeSortFunc(u, threshold, cmp.Compare[T])
+}
+
+// ParallelMergeSortFunc is the concurrent version of MergeSortFunc. Any slice
+// longer than threshold has its halves sorted at the same time, with the number
+// of extra goroutines capped so that at most GOMAXPROCS are sorting at once. The
+// result is exactly what MergeSortFunc would give you, stability and all.
+func ParallelMergeSortFunc[T any](u []T, threshold int, cmp func(a, b T) int) []T {
+	if threshold <= 0 {
+		threshold = DefaultParallelThreshold
+	}
+
+	// This is a buffered channel used as a semaphore. Each goroutine we spin up
+	// has to put a token in first, and takes it back out when it's done. The
+	// calling goroutine is already doing work, so it doesn't need a token, which
+	// is why this is one less than GOMAXPROCS.
+	tokens := make(chan struct{}, runtime.GOMAXPROCS(0)-1)
+
+	return parallelMergeSort(u, threshold, tokens, cmp)
+}
+
+func parallelMergeSort[T any](u []T, threshold int, tokens chan struct{}, cmp func(a, b T) int) []T {
+	// Too small to be worth it? Just sort it the boring way.
+	if len(u) <= threshold {
+		return MergeSortFunc(u, cmp)
+	}
+
+	half := len(u) / 2
+	var lsort, rsort []T
+
+	// The select with a default is what makes this non-blocking. If there's
+	// room in the tokens channel we get to spin up a goroutine, otherwise we
+	// fall through and do both halves ourselves.
+	select {
+	case tokens <- struct{}{}:
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// give the token back once we're done so somebody else can use it
+			defer func() { <-tokens }()
+			lsort = parallelMergeSort(u[:half], threshold, tokens, cmp)
+		}()
+
+		// While that's going, this goroutine sorts the right side.
+		rsort = parallelMergeSort(u[half:], threshold, tokens, cmp)
+
+		// And we can't merge until the left side is done!
+		wg.Wait()
+	default:
+		lsort = parallelMergeSort(u[:half], threshold, tokens, cmp)
+		rsort = parallelMergeSort(u[half:], threshold, tokens, cmp)
+	}
+
+	return merge(lsort, rsort, cmp)
+}
@@ -0,0 +1,70 @@
+package mergesort
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// benchSize is how big the slices in the benchmarks are. Small slices don't tell
+// you much, since the parallel version doesn't even kick in below the threshold.
+const benchSize = 1 << 20
+
+// randomInts makes n random ints. The seed is fixed so every run sorts the same data.
+func randomInts(n int) []int {
+	r := rand.New(rand.NewSource(42))
+	retval := make([]int, n)
+	for i := range retval {
+		retval[i] = r.Int()
+	}
+	return retval
+}
+
+// This is the one to run with -race. A small threshold means lots of goroutines
+// sorting lots of little pieces at the same time.
+func TestParallelMergeSortFunc(t *testing.T) {
+	for _, threshold := range []int{0, 1, 16, 1000} {
+		in := records(randomInts(50000)...)
+		for i := range in {
+			in[i].key %= 100 // plenty of ties, to check stability too
+		}
+
+		want := slices.Clone(in)
+		slices.SortStableFunc(want, byKey)
+
+		got := ParallelMergeSortFunc(slices.Clone(in), threshold, byKey)
+		if !slices.Equal(got, want) {
+			t.Errorf("threshold %d: ParallelMergeSortFunc doesn't match slices.SortStableFunc", threshold)
+		}
+	}
+}
+
+func TestParallelMergeSortEmpty(t *testing.T) {
+	if got := ParallelMergeSort([]int(nil), 1); len(got) != 0 {
+		t.Errorf("ParallelMergeSort(nil) = %v, want empty", got)
+	}
+}
+
+func BenchmarkParallelMergeSort(b *testing.B) {
+	data := randomInts(benchSize)
+	b.ResetTimer()
+	for range b.N {
+		ParallelMergeSort(slices.Clone(data), 0)
+	}
+}
+
+func BenchmarkMergeSort(b *testing.B) {
+	data := randomInts(benchSize)
+	b.ResetTimer()
+	for range b.N {
+		MergeSort(slices.Clone(data))
+	}
+}
+
+func BenchmarkSlicesSort(b *testing.B) {
+	data := randomInts(benchSize)
+	b.ResetTimer()
+	for range b.N {
+		slices.Sort(slices.Clone(data))
+	}
+}